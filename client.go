@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	cleanhttp "github.com/hashicorp/go-cleanhttp"
@@ -18,6 +19,14 @@ import (
 
 // AuthenticatedClient represents a Netatmo API client needed by the subpackages to query the API.
 // This package provides a reference implementation, see below.
+//
+// ExecuteNetatmoAPIRequest must respect the following contract, relied upon by the subpackages write calls:
+//   - urlValues are sent as the query string, except for POST requests without body where they must be sent
+//     as a form encoded body (application/x-www-form-urlencoded)
+//   - a non nil body must be sent as is with the application/json content type
+//   - the "body" member of the answer is unmarshaled into destination, which can be nil if it is not needed
+//   - errors reported by the API must be returned as HTTPStatusOKErrors or HTTPStatusGenericError
+//     (see IsNothingToModify())
 type AuthenticatedClient interface {
 	ExecuteNetatmoAPIRequest(ctx context.Context, method, endpoint string, urlValues url.Values,
 		body io.Reader, destination interface{}) (http.Header, RequestStats, error)
@@ -51,9 +60,9 @@ func init() {
 // Do not instantiate directly, use ExecuteNetatmoAPIReaquest(),
 // NewClientWithClientCredentials() or NewClientWithToken() instead.
 type Controller struct {
-	ctx   context.Context
-	token *oauth2.Token
-	http  *http.Client
+	ctx    context.Context
+	tokens *tokensSource
+	http   *http.Client
 }
 
 // NewClientWithAuthorizationCode returns an initialized and ready to use Netatmo API client.
@@ -71,7 +80,8 @@ func NewClientWithAuthorizationCode(ctx context.Context, oac oauth2.Config, auth
 		ctx: context.WithValue(ctx, oauth2.HTTPClient, customClient),
 	}
 	// Exchange auth code for access & refresh token
-	if c.token, err = oac.Exchange(c.ctx, authCode,
+	var token *oauth2.Token
+	if token, err = oac.Exchange(c.ctx, authCode,
 		oauth2.SetAuthURLParam("scope", strings.Join(oac.Scopes, " ")),
 		oauth2.SetAuthURLParam("redirect_uri", oac.RedirectURL),
 	); err != nil {
@@ -79,7 +89,7 @@ func NewClientWithAuthorizationCode(ctx context.Context, oac oauth2.Config, auth
 		return
 	}
 	// Generate the oauth2 enabled http client
-	c.http = oac.Client(c.ctx, c.token)
+	c.initHTTPClient(oac, token)
 	// Return the initialized controller as Client
 	client = c
 	return
@@ -98,12 +108,13 @@ func NewClientWithClientCredentials(ctx context.Context, oac oauth2.Config, user
 		ctx: context.WithValue(ctx, oauth2.HTTPClient, customClient),
 	}
 	// Get tokens with credentials loging
-	if c.token, err = oac.PasswordCredentialsToken(c.ctx, username, password); err != nil {
+	var token *oauth2.Token
+	if token, err = oac.PasswordCredentialsToken(c.ctx, username, password); err != nil {
 		err = fmt.Errorf("can not get oauth2 tokens with client credentials: %w", err)
 		return
 	}
 	// Generate the oauth2 enabled http client
-	c.http = oac.Client(c.ctx, c.token)
+	c.initHTTPClient(oac, token)
 	// Return the initialized controller as Client
 	client = c
 	return
@@ -126,22 +137,72 @@ func NewClientWithTokens(ctx context.Context, oac oauth2.Config, previousTokens
 		err = errors.New("can not create a client with nil tokens")
 		return
 	}
-	c.token = previousTokens
 	// Generate the oauth2 enabled http client
-	c.http = oac.Client(c.ctx, c.token)
+	c.initHTTPClient(oac, previousTokens)
 	// Return the initialized controller as Client
 	client = c
 	return
 }
 
-// GetTokens returns a copy of the client tokens.
-// Might not be safe to call to use while the client is used, use to save state once
-// the client is not used any more (ex: stopping/shutdown)
+// GetTokens returns a copy of the client tokens, including the ones refreshed automatically.
+// It is safe to call while the client is used.
 func (c *Controller) GetTokens() (tokens oauth2.Token) {
-	return *c.token
+	return c.tokens.current()
 }
 
-// ExecuteNetatmoAPIRequest takes care of all the HTTP logic as well as JSON parsing and error handling
+// OnTokensRefresh registers a callback called (synchronously, within the request triggering the refresh)
+// each time the tokens are refreshed. It allows to save them as soon as they change.
+func (c *Controller) OnTokensRefresh(callback func(tokens oauth2.Token)) {
+	c.tokens.onRefresh(callback)
+}
+
+func (c *Controller) initHTTPClient(oac oauth2.Config, token *oauth2.Token) {
+	c.tokens = &tokensSource{
+		source: oac.TokenSource(c.ctx, token),
+		last:   *token,
+	}
+	c.http = oauth2.NewClient(c.ctx, c.tokens)
+}
+
+// tokensSource keeps track of the tokens refreshed by the underlying oauth2 token source
+type tokensSource struct {
+	source   oauth2.TokenSource
+	access   sync.Mutex
+	last     oauth2.Token
+	callback func(tokens oauth2.Token)
+}
+
+// Token implements the oauth2.TokenSource interface
+func (ts *tokensSource) Token() (token *oauth2.Token, err error) {
+	if token, err = ts.source.Token(); err != nil {
+		return
+	}
+	ts.access.Lock()
+	refreshed := token.AccessToken != ts.last.AccessToken
+	ts.last = *token
+	callback := ts.callback
+	ts.access.Unlock()
+	if refreshed && callback != nil {
+		callback(*token)
+	}
+	return
+}
+
+func (ts *tokensSource) current() oauth2.Token {
+	ts.access.Lock()
+	defer ts.access.Unlock()
+	return ts.last
+}
+
+func (ts *tokensSource) onRefresh(callback func(tokens oauth2.Token)) {
+	ts.access.Lock()
+	ts.callback = callback
+	ts.access.Unlock()
+}
+
+// ExecuteNetatmoAPIRequest takes care of all the HTTP logic as well as JSON parsing and error handling.
+// A non nil body is sent as JSON. For POST requests without body, urlValues are sent as a form encoded body.
+// destination can be nil if the response body is not needed.
 func (c *Controller) ExecuteNetatmoAPIRequest(ctx context.Context, method, endpoint string,
 	urlValues url.Values, body io.Reader, destination interface{}) (headers http.Header,
 	rs RequestStats, err error) {
@@ -150,6 +211,15 @@ func (c *Controller) ExecuteNetatmoAPIRequest(ctx context.Context, method, endpo
 		ctx = c.ctx
 	}
 	// Forge request
+	var contentType string
+	switch {
+	case body != nil:
+		contentType = "application/json"
+	case method == http.MethodPost:
+		body = strings.NewReader(urlValues.Encode())
+		contentType = "application/x-www-form-urlencoded"
+		urlValues = nil
+	}
 	reqUrl := *netatmoAPIURL
 	reqUrl.Path += endpoint
 	reqUrl.RawQuery = urlValues.Encode()
@@ -159,6 +229,9 @@ func (c *Controller) ExecuteNetatmoAPIRequest(ctx context.Context, method, endpo
 		return
 	}
 	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
 	req.Header.Set("User-Agent", "github.com/hekmon/go-netatmo")
 	// Execute request
 	resp, err := c.http.Do(req)
@@ -223,6 +296,9 @@ func (c *Controller) ExecuteNetatmoAPIRequest(ctx context.Context, method, endpo
 		return
 	}
 	// Unmarshall body to dest
+	if destination == nil {
+		return
+	}
 	if err = json.Unmarshal(unparsedBody, destination); err != nil {
 		err = fmt.Errorf("request successful but can not parse body as JSON: %w", err)
 	}
@@ -0,0 +1,123 @@
+// netatmo-exporter is a prometheus exporter for the Netatmo weather stations.
+// Stations data is polled periodically and cached: scrapes never trigger API calls.
+//
+// At first start, tokens are retrieved with the client credentials workflow (username and password)
+// and saved to the tokens file. Next starts only need the tokens file, which is updated on each tokens refresh.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hekmon/go-netatmo"
+	"github.com/hekmon/go-netatmo/internal/cliauth"
+	"github.com/hekmon/go-netatmo/weather"
+)
+
+func main() {
+	// Flags
+	listen := flag.String("listen", ":9210", "address to listen to for prometheus scrapes")
+	interval := flag.Duration("interval", weather.StationMeasureInterval, "interval between two polls of the stations data")
+	tokensFile := flag.String("tokens", "netatmo-tokens.json", "file used to load and save the oauth2 tokens")
+	flag.Parse()
+	// Init client
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	client, err := cliauth.NewClient(ctx, *tokensFile, netatmo.ScopeStationRead)
+	if err != nil {
+		log.Fatalf("can not initialize the netatmo client: %s", err)
+	}
+	// Start poller
+	c := &cache{
+		client:   weather.New(client),
+		interval: *interval,
+	}
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		c.run(ctx)
+	}()
+	// Start HTTP server
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", c)
+	server := &http.Server{
+		Addr:    *listen,
+		Handler: mux,
+	}
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		<-ctx.Done()
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("HTTP server shutdown failed: %s", err)
+		}
+	}()
+	log.Printf("listening on %s", *listen)
+	if err = server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("HTTP server failed: %s", err)
+		cancel()
+	}
+	workers.Wait()
+}
+
+// cache polls the stations data and serves the last result to prometheus
+type cache struct {
+	client   *weather.Client
+	interval time.Duration
+	// last poll
+	access   sync.RWMutex
+	registry registry
+	lastPoll time.Time
+	lastErr  error
+}
+
+func (c *cache) run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		c.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *cache) poll(ctx context.Context) {
+	data, _, _, err := c.client.GetStationData(ctx, weather.GetStationDataParameters{})
+	c.access.Lock()
+	defer c.access.Unlock()
+	c.lastPoll = time.Now()
+	if c.lastErr = err; err != nil {
+		log.Printf("can not get stations data: %s", err)
+		return
+	}
+	c.registry = newRegistry(data)
+}
+
+// ServeHTTP implements the http.Handler interface
+func (c *cache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.access.RLock()
+	defer c.access.RUnlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := c.registry.write(w); err != nil {
+		log.Printf("can not write metrics: %s", err)
+		return
+	}
+	fmt.Fprintf(w, "# HELP netatmo_up 1 if the last poll of the Netatmo API was successful\n# TYPE netatmo_up gauge\nnetatmo_up %v\n",
+		boolToFloat(c.lastErr == nil && !c.lastPoll.IsZero()))
+	fmt.Fprintf(w, "# HELP netatmo_last_poll_timestamp_seconds Unix time of the last poll of the Netatmo API\n# TYPE netatmo_last_poll_timestamp_seconds gauge\nnetatmo_last_poll_timestamp_seconds %d\n",
+		c.lastPoll.Unix())
+}
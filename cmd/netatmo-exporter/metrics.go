@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hekmon/go-netatmo/weather"
+)
+
+// metric represents a prometheus gauge family
+type metric struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+// sample represents a single labeled value of a gauge
+type sample struct {
+	labels    labels
+	value     float64
+	timestamp time.Time // zero value means no timestamp
+}
+
+type labels struct {
+	home    string
+	station string
+	module  string
+	mtype   weather.ModuleType
+}
+
+func (l labels) String() string {
+	return fmt.Sprintf(`home="%s",station="%s",module="%s",type="%s"`,
+		escapeLabel(l.home), escapeLabel(l.station), escapeLabel(l.module), escapeLabel(string(l.mtype)))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+// registry gathers the samples of a poll by metric name
+type registry map[string]*metric
+
+func (r registry) add(name, help string, l labels, value float64, timestamp time.Time) {
+	m, found := r[name]
+	if !found {
+		m = &metric{
+			name: name,
+			help: help,
+		}
+		r[name] = m
+	}
+	m.samples = append(m.samples, sample{
+		labels:    l,
+		value:     value,
+		timestamp: timestamp,
+	})
+}
+
+func boolToFloat(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// write outputs the registry using the prometheus text exposition format
+func (r registry) write(w io.Writer) (err error) {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		m := r[name]
+		if _, err = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", m.name, m.help, m.name); err != nil {
+			return
+		}
+		for _, s := range m.samples {
+			line := fmt.Sprintf("%s{%s} %s", m.name, s.labels, strconv.FormatFloat(s.value, 'g', -1, 64))
+			if !s.timestamp.IsZero() {
+				line += " " + strconv.FormatInt(s.timestamp.UnixNano()/int64(time.Millisecond), 10)
+			}
+			if _, err = fmt.Fprintln(w, line); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// newRegistry converts the station data into prometheus gauges
+func newRegistry(data weather.StationDataBody) (r registry) {
+	r = make(registry)
+	for _, station := range data.Devices {
+		l := labels{
+			home:    station.HomeName,
+			station: station.ModuleName,
+			module:  station.ModuleName,
+			mtype:   station.Type,
+		}
+		// status (not timestamped: the last status time of an unreachable device is too old to be ingested)
+		r.add("netatmo_reachable", "1 if the device is reachable", l, boolToFloat(station.Reachable), time.Time{})
+		r.add("netatmo_wifi_status", "WiFi signal quality (lower is better)", l, float64(station.WifiStatus), time.Time{})
+		// measures
+		if station.DashboardData.IsSet() {
+			dd := station.DashboardData
+			r.add("netatmo_temperature_celsius", "Temperature in °C", l, dd.Temperature, dd.Time)
+			r.add("netatmo_humidity_percent", "Humidity in %", l, float64(dd.Humidity), dd.Time)
+			r.add("netatmo_co2_ppm", "CO2 level in ppm", l, float64(dd.CO2), dd.Time)
+			r.add("netatmo_noise_db", "Noise level in dB", l, float64(dd.Noise), dd.Time)
+			r.add("netatmo_pressure_mbar", "Pressure in mbar", l, dd.Pressure, dd.Time)
+			r.add("netatmo_absolute_pressure_mbar", "Absolute pressure in mbar", l, dd.AbsolutePressure, dd.Time)
+		}
+		// modules
+		for _, module := range station.Modules {
+			l.module = module.ModuleName
+			l.mtype = module.Type
+			addModule(r, l, module)
+		}
+	}
+	return
+}
+
+func addModule(r registry, l labels, module weather.Module) {
+	// status (not timestamped, see newRegistry())
+	r.add("netatmo_reachable", "1 if the device is reachable", l, boolToFloat(module.Reachable), time.Time{})
+	r.add("netatmo_rf_status", "Radio signal quality (lower is better)", l, float64(module.RfStatus), time.Time{})
+	r.add("netatmo_battery_percent", "Battery remaining in %", l, float64(module.BatteryPercent), time.Time{})
+	r.add("netatmo_battery_vp", "Battery level in mV", l, float64(module.BatteryVp), time.Time{})
+	// measures
+	if dd := module.DashboardDataOutdoor; dd != nil {
+		r.add("netatmo_temperature_celsius", "Temperature in °C", l, dd.Temperature, dd.Time)
+		r.add("netatmo_humidity_percent", "Humidity in %", l, float64(dd.Humidity), dd.Time)
+	}
+	if dd := module.DashboardDataIndoor; dd != nil {
+		r.add("netatmo_temperature_celsius", "Temperature in °C", l, dd.Temperature, dd.Time)
+		r.add("netatmo_humidity_percent", "Humidity in %", l, float64(dd.Humidity), dd.Time)
+		r.add("netatmo_co2_ppm", "CO2 level in ppm", l, float64(dd.CO2), dd.Time)
+	}
+	if dd := module.DashboardDataWind; dd != nil {
+		r.add("netatmo_wind_strength_kph", "Wind strength in km/h", l, float64(dd.WindStrength), dd.Time)
+		r.add("netatmo_wind_angle_degrees", "Wind angle in degrees", l, float64(dd.WindAngle), dd.Time)
+		r.add("netatmo_gust_strength_kph", "Gust strength in km/h", l, float64(dd.GustStrength), dd.Time)
+		r.add("netatmo_gust_angle_degrees", "Gust angle in degrees", l, float64(dd.GustAngle), dd.Time)
+	}
+	if dd := module.DashboardDataRain; dd != nil {
+		r.add("netatmo_rain_mm", "Rain in mm for the last measure", l, dd.Rain, dd.Time)
+		r.add("netatmo_rain_1h_mm", "Rain in mm for the last hour", l, dd.SumRain1, dd.Time)
+		r.add("netatmo_rain_24h_mm", "Rain in mm for the last 24 hours", l, dd.SumRain24, dd.Time)
+	}
+}
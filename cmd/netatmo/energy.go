@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hekmon/go-netatmo"
+	"github.com/hekmon/go-netatmo/energy"
+	"github.com/hekmon/go-netatmo/energy/schedulesync"
+	"github.com/hekmon/go-netatmo/internal/cliauth"
+)
+
+func energySchedule(ctx context.Context, args []string) (err error) {
+	if len(args) < 1 || (args[0] != "plan" && args[0] != "apply") {
+		return errors.New("expecting a sub command: plan or apply")
+	}
+	action := args[0]
+	flags := flag.NewFlagSet("energy schedule "+action, flag.ContinueOnError)
+	tokensFile := flags.String("tokens", "netatmo-tokens.json", "file used to load and save the oauth2 tokens")
+	scheduleFile := flags.String("file", "schedules.yaml", "declarative schedules file (YAML or JSON)")
+	dryRun := flags.Bool("dry-run", false, "apply: print the plan without sending any change")
+	if err = flags.Parse(args[1:]); err != nil {
+		return
+	}
+	// Prepare
+	file, err := schedulesync.LoadFile(*scheduleFile)
+	if err != nil {
+		return
+	}
+	scopes := []string{netatmo.ScopeThermostatRead}
+	if action == "apply" && !*dryRun {
+		scopes = append(scopes, netatmo.ScopeThermostatWrite)
+	}
+	client, err := cliauth.NewClient(ctx, *tokensFile, scopes...)
+	if err != nil {
+		return
+	}
+	syncer := schedulesync.New(energy.New(client), schedulesync.Config{
+		DryRun: action == "plan" || *dryRun,
+	})
+	// Plan
+	plan, err := syncer.Plan(ctx, file)
+	if err != nil {
+		return
+	}
+	if _, err = plan.WriteTo(os.Stdout); err != nil {
+		return
+	}
+	if action == "plan" || *dryRun || plan.Empty() {
+		return
+	}
+	// Apply
+	results, err := syncer.Apply(ctx, plan)
+	for _, result := range results {
+		switch {
+		case result.Synced && result.Switched:
+			fmt.Printf("schedule '%s' of home '%s': synced and selected\n", result.Change.Desired.Name, result.Change.Home.Name)
+		case result.Synced:
+			fmt.Printf("schedule '%s' of home '%s': synced\n", result.Change.Desired.Name, result.Change.Home.Name)
+		case result.Switched:
+			fmt.Printf("schedule '%s' of home '%s': selected\n", result.Change.Desired.Name, result.Change.Home.Name)
+		}
+	}
+	return
+}
@@ -0,0 +1,49 @@
+// netatmo is a command line tool built on top of the go-netatmo packages.
+//
+// Usage:
+//
+//	netatmo weather export [flags]
+//	netatmo energy schedule plan|apply [flags]
+//
+// At first start, tokens are retrieved with the client credentials workflow (NETATMO_USERNAME and
+// NETATMO_PASSWORD env vars) and saved to the tokens file. Next starts only need the tokens file.
+// NETATMO_CLIENT_ID and NETATMO_CLIENT_SECRET env vars must always be set.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const usage = `usage: netatmo <product> <command> [flags]
+
+products and commands:
+	weather export	export stations, public or measures data as InfluxDB line protocol or CSV
+	energy schedule	plan or apply a declarative heating schedules file (energy schedule plan|apply [flags])
+`
+
+func main() {
+	if len(os.Args) < 3 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	var err error
+	switch os.Args[1] + " " + os.Args[2] {
+	case "weather export":
+		err = weatherExport(ctx, os.Args[3:])
+	case "energy schedule":
+		err = energySchedule(ctx, os.Args[3:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s: %s\n", os.Args[1], os.Args[2], err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hekmon/go-netatmo"
+	"github.com/hekmon/go-netatmo/internal/cliauth"
+	"github.com/hekmon/go-netatmo/weather"
+)
+
+// recordsWriter is satisfied by both weather.InfluxWriter and weather.CSVWriter
+type recordsWriter interface {
+	WriteRecords(records []weather.ExportRecord) error
+}
+
+func weatherExport(ctx context.Context, args []string) (err error) {
+	flags := flag.NewFlagSet("weather export", flag.ContinueOnError)
+	tokensFile := flags.String("tokens", "netatmo-tokens.json", "file used to load and save the oauth2 tokens")
+	format := flags.String("format", "influx", "output format: influx or csv")
+	source := flags.String("source", "station", "data to export: station, public or measure")
+	measurement := flags.String("measurement", weather.DefaultInfluxMeasurement, "influx: measurement name")
+	columns := flags.String("columns", strings.Join(weather.DefaultCSVColumns, ","), "csv: comma separated list of columns")
+	timezone := flags.String("timezone", "UTC", "csv: time zone used to format the time column")
+	latNE := flags.Float64("lat-ne", 0, "public: north east corner latitude")
+	lonNE := flags.Float64("lon-ne", 0, "public: north east corner longitude")
+	latSW := flags.Float64("lat-sw", 0, "public: south west corner latitude")
+	lonSW := flags.Float64("lon-sw", 0, "public: south west corner longitude")
+	required := flags.String("required", "", "public: comma separated list of required data (temperature, humidity, pressure, rain, wind)")
+	deviceID := flags.String("device", "", "measure: station ID")
+	moduleID := flags.String("module", "", "measure: module ID (empty for the station itself)")
+	scale := flags.String("scale", string(weather.MeasureScale30Min), "measure: scale")
+	types := flags.String("types", string(weather.MeasureTypeTemperature), "measure: comma separated list of measure types")
+	begin := flags.String("begin", "", "measure: RFC3339 start time")
+	end := flags.String("end", "", "measure: RFC3339 end time")
+	if err = flags.Parse(args); err != nil {
+		return
+	}
+	// Prepare writer
+	var writer recordsWriter
+	switch *format {
+	case "influx":
+		iw := weather.NewInfluxWriter(os.Stdout)
+		iw.Measurement = *measurement
+		writer = iw
+	case "csv":
+		cw := weather.NewCSVWriter(os.Stdout)
+		cw.Columns = strings.Split(*columns, ",")
+		if cw.Location, err = time.LoadLocation(*timezone); err != nil {
+			return fmt.Errorf("invalid time zone: %w", err)
+		}
+		writer = cw
+	default:
+		return fmt.Errorf("unknown format: %s", *format)
+	}
+	// Retrieve data
+	client, err := cliauth.NewClient(ctx, *tokensFile, netatmo.ScopeStationRead)
+	if err != nil {
+		return
+	}
+	wc := weather.New(client)
+	var records []weather.ExportRecord
+	switch *source {
+	case "station":
+		var data weather.StationDataBody
+		if data, _, _, err = wc.GetStationData(ctx, weather.GetStationDataParameters{}); err != nil {
+			return
+		}
+		records = weather.StationDataRecords(data)
+	case "public":
+		params := weather.GetPublicDataParameters{
+			NorthEastLatitude:  *latNE,
+			NorthEastLongitude: *lonNE,
+			SouthWestLatitude:  *latSW,
+			SouthWestLongitude: *lonSW,
+		}
+		if params.RequiredData, err = weather.ParseRequiredData(*required); err != nil {
+			return
+		}
+		var stations []weather.PublicStationData
+		if stations, _, _, err = wc.GetPublicData(ctx, params); err != nil {
+			return
+		}
+		records = weather.PublicDataRecords(stations)
+	case "measure":
+		params := weather.GetMeasureParameters{
+			DeviceID: *deviceID,
+			ModuleID: *moduleID,
+			Scale:    weather.MeasureScale(*scale),
+		}
+		for _, measureType := range strings.Split(*types, ",") {
+			params.Types = append(params.Types, weather.MeasureType(measureType))
+		}
+		if params.DateBegin, err = parseOptionalTime(*begin); err != nil {
+			return fmt.Errorf("invalid begin time: %w", err)
+		}
+		if params.DateEnd, err = parseOptionalTime(*end); err != nil {
+			return fmt.Errorf("invalid end time: %w", err)
+		}
+		var series weather.MeasureSeries
+		if series, _, _, err = wc.GetMeasure(ctx, params); err != nil {
+			return
+		}
+		base := weather.ExportRecord{
+			StationID: params.DeviceID,
+			ModuleID:  params.ModuleID,
+		}
+		if base.ModuleID == "" {
+			base.ModuleID = params.DeviceID
+		}
+		records = weather.MeasureSeriesRecords(series, base)
+	default:
+		return errors.New("unknown source: " + *source)
+	}
+	// Write
+	return writer.WriteRecords(records)
+}
+
+func parseOptionalTime(value string) (t time.Time, err error) {
+	if value == "" {
+		return
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,54 @@
+// Package analytics computes heating statistics from the energy measures history: boiler runtime and duty
+// cycle, rooms heat up rate, time to setpoint and overshoot, rooms never reaching their setpoint, open windows
+// correlation and a degree days normalised consumption using the outdoor temperature of a weather station.
+//
+// Inputs are the series returned by energy.Client.GetMeasure() (boiler), energy.Client.GetRoomMeasure()
+// (rooms temperatures), energy.Evaluator.Transitions() (rooms setpoints) and weather.Client.GetMeasure()
+// (outdoor temperature). All computations are pure and do not call the API.
+package analytics
+
+import "time"
+
+const (
+	// DefaultReachTolerance is the default temperature gap below the setpoint considered as reached (°C)
+	DefaultReachTolerance = 0.2
+	// DefaultMinHeatUpDelta is the default minimum setpoint rise over the room temperature to detect a heat up (°C)
+	DefaultMinHeatUpDelta = 1
+	// DefaultDegreeDaysBase is the default base temperature of the heating degree days (°C)
+	DefaultDegreeDaysBase = 18
+)
+
+// Config allows to tune the analytics, zero or negative values are replaced by the defaults
+type Config struct {
+	ReachTolerance float64        // temperature gap below the setpoint considered as reached (°C)
+	MinHeatUpDelta float64        // minimum gap between the setpoint and the room temperature to consider a heat up (°C)
+	DegreeDaysBase float64        // base temperature of the heating degree days (°C)
+	Location       *time.Location // location used for the days boundaries (UTC if nil, use the home time zone)
+}
+
+func (c Config) withDefaults() Config {
+	if c.ReachTolerance <= 0 {
+		c.ReachTolerance = DefaultReachTolerance
+	}
+	if c.MinHeatUpDelta <= 0 {
+		c.MinHeatUpDelta = DefaultMinHeatUpDelta
+	}
+	if c.DegreeDaysBase <= 0 {
+		c.DegreeDaysBase = DefaultDegreeDaysBase
+	}
+	if c.Location == nil {
+		c.Location = time.UTC
+	}
+	return c
+}
+
+// dayStart returns the local midnight of the day of t
+func dayStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// nextDay returns the local midnight following day (DST safe)
+func nextDay(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, day.Location())
+}
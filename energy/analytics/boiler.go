@@ -0,0 +1,140 @@
+package analytics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hekmon/go-netatmo/energy"
+	"github.com/hekmon/go-netatmo/weather"
+)
+
+// BoilerDay contains the boiler activity of a local day
+type BoilerDay struct {
+	Start     time.Time     // local midnight
+	End       time.Time     // next local midnight
+	Runtime   time.Duration // time the boiler was on
+	Observed  time.Duration // time covered by the measures (on + off when available, the day length otherwise)
+	DutyCycle float64       // Runtime / Observed (0 to 1)
+}
+
+// BoilerDays computes the daily runtime and duty cycle from a boiler series containing the
+// energy.MeasureTypeSumBoilerOn type (and optionally energy.MeasureTypeSumBoilerOff). Days are sorted.
+func BoilerDays(series energy.MeasureSeries, conf Config) (days []BoilerDay, err error) {
+	conf = conf.withDefaults()
+	onIndex, offIndex := -1, -1
+	for index, measureType := range series.Types {
+		switch measureType {
+		case energy.MeasureTypeSumBoilerOn:
+			onIndex = index
+		case energy.MeasureTypeSumBoilerOff:
+			offIndex = index
+		}
+	}
+	if onIndex == -1 {
+		err = errors.New("the series does not contain the sum_boiler_on measure type")
+		return
+	}
+	var current *BoilerDay
+	var off time.Duration
+	closeDay := func() {
+		if current == nil {
+			return
+		}
+		if offIndex == -1 {
+			current.Observed = current.End.Sub(current.Start)
+		} else {
+			current.Observed = current.Runtime + off
+		}
+		if current.Observed > 0 {
+			current.DutyCycle = float64(current.Runtime) / float64(current.Observed)
+		}
+		days = append(days, *current)
+	}
+	for _, point := range series.Points {
+		if point.Values[onIndex] == nil {
+			continue
+		}
+		start := dayStart(point.Time, conf.Location)
+		if current == nil || !current.Start.Equal(start) {
+			closeDay()
+			current = &BoilerDay{Start: start, End: nextDay(start)}
+			off = 0
+		}
+		current.Runtime += time.Duration(*point.Values[onIndex] * float64(time.Second))
+		if offIndex != -1 && point.Values[offIndex] != nil {
+			off += time.Duration(*point.Values[offIndex] * float64(time.Second))
+		}
+	}
+	closeDay()
+	return
+}
+
+// DegreeDay contains the heating degree days and the boiler runtime of a local day
+type DegreeDay struct {
+	Start          time.Time
+	End            time.Time
+	OutdoorMean    float64       // mean outdoor temperature (°C)
+	OutdoorSamples int           // number of outdoor measures used for the mean
+	HDD            float64       // heating degree days: base - mean when positive, 0 otherwise
+	Runtime        time.Duration // boiler runtime
+}
+
+// DegreeDaysReport normalises the boiler consumption with the heating degree days
+type DegreeDaysReport struct {
+	Base                float64 // base temperature (°C)
+	Days                []DegreeDay
+	HDD                 float64       // total heating degree days
+	Runtime             time.Duration // total boiler runtime
+	RuntimePerDegreeDay time.Duration // boiler runtime needed for one degree day, 0 if there was no degree day
+}
+
+// Estimate returns the expected boiler runtime for a period of hdd heating degree days
+func (ddr DegreeDaysReport) Estimate(hdd float64) time.Duration {
+	return time.Duration(hdd * float64(ddr.RuntimePerDegreeDay))
+}
+
+// DegreeDays matches the boiler days with the daily mean of an outdoor temperature series (as returned by the
+// weather getmeasure on an outdoor module with weather.MeasureTypeTemperature). Days without outdoor data are skipped.
+func DegreeDays(boilerDays []BoilerDay, outdoor weather.MeasureSeries, conf Config) (report DegreeDaysReport, err error) {
+	conf = conf.withDefaults()
+	tempIndex := -1
+	for index, measureType := range outdoor.Types {
+		if measureType == weather.MeasureTypeTemperature {
+			tempIndex = index
+		}
+	}
+	if tempIndex == -1 {
+		err = errors.New("the outdoor series does not contain the temperature measure type")
+		return
+	}
+	report.Base = conf.DegreeDaysBase
+	for _, boilerDay := range boilerDays {
+		day := DegreeDay{
+			Start:   boilerDay.Start,
+			End:     boilerDay.End,
+			Runtime: boilerDay.Runtime,
+		}
+		var sum float64
+		for _, point := range outdoor.Points {
+			if point.Time.Before(day.Start) || !point.Time.Before(day.End) || point.Values[tempIndex] == nil {
+				continue
+			}
+			sum += *point.Values[tempIndex]
+			day.OutdoorSamples++
+		}
+		if day.OutdoorSamples == 0 {
+			continue
+		}
+		day.OutdoorMean = sum / float64(day.OutdoorSamples)
+		if day.OutdoorMean < report.Base {
+			day.HDD = report.Base - day.OutdoorMean
+		}
+		report.Days = append(report.Days, day)
+		report.HDD += day.HDD
+		report.Runtime += day.Runtime
+	}
+	if report.HDD > 0 {
+		report.RuntimePerDegreeDay = time.Duration(float64(report.Runtime) / report.HDD)
+	}
+	return
+}
@@ -0,0 +1,149 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/hekmon/go-netatmo/energy"
+)
+
+// HeatUp describes how a room reached (or not) a setpoint higher than its temperature
+type HeatUp struct {
+	RoomID           energy.RoomID
+	Start            time.Time     // setpoint change
+	End              time.Time     // next setpoint change (or last measure)
+	Setpoint         float64       // target temperature (°C)
+	StartTemperature float64       // room temperature at the setpoint change (°C)
+	Reached          bool          // the room reached the setpoint (minus the reach tolerance) before the end
+	ReachedAt        time.Time     // zero if not reached
+	TimeToSetpoint   time.Duration // 0 if not reached
+	Rate             float64       // heat up rate until the setpoint is reached, or until the end if not (°C/hour)
+	Overshoot        float64       // maximum temperature above the setpoint after it was reached (°C)
+	OpenWindow       bool          // an open window has been detected during the heat up
+}
+
+// HeatUps detects the heat up episodes of a room. temperatures must contain the energy.MeasureTypeTemperature
+// type (see energy.Client.GetRoomMeasure()) and setpoints are the successive setpoints of the room (see
+// energy.Evaluator.Transitions()). windows can be nil. Off and max (boost) setpoints are not heat ups.
+func HeatUps(roomID energy.RoomID, temperatures energy.MeasureSeries, setpoints []energy.Setpoint,
+	windows []OpenWindowEvent, conf Config) (heatUps []HeatUp) {
+	conf = conf.withDefaults()
+	values := temperatures.Values(energy.MeasureTypeTemperature)
+	if len(values) == 0 {
+		return
+	}
+	for _, setpoint := range setpoints {
+		if setpoint.RoomID != roomID || setpoint.Mode == energy.SetpointModeOff || setpoint.Mode == energy.SetpointModeMax {
+			continue
+		}
+		startTemp, found := temperatures.At(energy.MeasureTypeTemperature, setpoint.From)
+		if !found || setpoint.Temperature-startTemp.Value < conf.MinHeatUpDelta {
+			continue
+		}
+		heatUp := HeatUp{
+			RoomID:           roomID,
+			Start:            setpoint.From,
+			End:              setpoint.Until,
+			Setpoint:         setpoint.Temperature,
+			StartTemperature: startTemp.Value,
+		}
+		if heatUp.End.IsZero() {
+			heatUp.End = values[len(values)-1].Time
+		}
+		var last energy.MeasureValue
+		for _, value := range values {
+			if !value.Time.After(heatUp.Start) || value.Time.After(heatUp.End) {
+				continue
+			}
+			last = value
+			if !heatUp.Reached && value.Value >= heatUp.Setpoint-conf.ReachTolerance {
+				heatUp.Reached = true
+				heatUp.ReachedAt = value.Time
+				heatUp.TimeToSetpoint = value.Time.Sub(heatUp.Start)
+				heatUp.Rate = rate(heatUp.StartTemperature, value, heatUp.Start)
+			}
+			if heatUp.Reached && value.Value-heatUp.Setpoint > heatUp.Overshoot {
+				heatUp.Overshoot = value.Value - heatUp.Setpoint
+			}
+		}
+		if last.Time.IsZero() {
+			// no measure during the episode
+			continue
+		}
+		if !heatUp.Reached {
+			heatUp.Rate = rate(heatUp.StartTemperature, last, heatUp.Start)
+		}
+		for _, window := range windows {
+			if window.RoomID == roomID && window.Overlaps(heatUp.Start, heatUp.End) {
+				heatUp.OpenWindow = true
+				break
+			}
+		}
+		heatUps = append(heatUps, heatUp)
+	}
+	return
+}
+
+func rate(startTemp float64, value energy.MeasureValue, start time.Time) float64 {
+	hours := value.Time.Sub(start).Hours()
+	if hours <= 0 {
+		return 0
+	}
+	return (value.Value - startTemp) / hours
+}
+
+// RoomReport summarises the heat up episodes of a room
+type RoomReport struct {
+	RoomID             energy.RoomID
+	HeatUps            int           // number of heat up episodes
+	Reached            int           // number of episodes which reached the setpoint
+	NeverReached       bool          // the room had heat up episodes but never reached its setpoint
+	MeanRate           float64       // mean heat up rate of the episodes without open window (°C/hour)
+	MeanTimeToSetpoint time.Duration // mean time to setpoint of the reached episodes without open window
+	MaxOvershoot       float64       // maximum overshoot (°C)
+	OpenWindowHeatUps  int           // number of episodes with an open window
+	OpenWindowMeanRate float64       // mean heat up rate of the episodes with an open window (°C/hour)
+}
+
+// Summarize aggregates the heat up episodes of a room (see HeatUps())
+func Summarize(roomID energy.RoomID, heatUps []HeatUp) (report RoomReport) {
+	report.RoomID = roomID
+	var (
+		rateSum, windowRateSum float64
+		rateCount, timeCount   int
+		timeSum                time.Duration
+	)
+	for _, heatUp := range heatUps {
+		if heatUp.RoomID != roomID {
+			continue
+		}
+		report.HeatUps++
+		if heatUp.Reached {
+			report.Reached++
+		}
+		if heatUp.Overshoot > report.MaxOvershoot {
+			report.MaxOvershoot = heatUp.Overshoot
+		}
+		if heatUp.OpenWindow {
+			report.OpenWindowHeatUps++
+			windowRateSum += heatUp.Rate
+			continue
+		}
+		rateSum += heatUp.Rate
+		rateCount++
+		if heatUp.Reached {
+			timeSum += heatUp.TimeToSetpoint
+			timeCount++
+		}
+	}
+	report.NeverReached = report.HeatUps > 0 && report.Reached == 0
+	if rateCount > 0 {
+		report.MeanRate = rateSum / float64(rateCount)
+	}
+	if timeCount > 0 {
+		report.MeanTimeToSetpoint = timeSum / time.Duration(timeCount)
+	}
+	if report.OpenWindowHeatUps > 0 {
+		report.OpenWindowMeanRate = windowRateSum / float64(report.OpenWindowHeatUps)
+	}
+	return
+}
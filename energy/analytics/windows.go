@@ -0,0 +1,65 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hekmon/go-netatmo/energy"
+)
+
+// OpenWindowEvent is a period during which an open window has been detected in a room
+type OpenWindowEvent struct {
+	RoomID energy.RoomID
+	Start  time.Time
+	End    time.Time // zero if the window is still open
+}
+
+// Overlaps returns true if the event overlaps [start, end]
+func (owe OpenWindowEvent) Overlaps(start, end time.Time) bool {
+	return !owe.Start.After(end) && (owe.End.IsZero() || !owe.End.Before(start))
+}
+
+// OpenWindowRecorder builds the open window events from successive home status. The API does not provide an
+// history of the open windows detections: record each status retrieved with energy.Client.GetHomeStatus().
+// An OpenWindowRecorder is not safe for concurrent use.
+type OpenWindowRecorder struct {
+	open   map[energy.RoomID]time.Time
+	events []OpenWindowEvent
+}
+
+// NewOpenWindowRecorder returns an empty recorder
+func NewOpenWindowRecorder() *OpenWindowRecorder {
+	return &OpenWindowRecorder{
+		open: make(map[energy.RoomID]time.Time),
+	}
+}
+
+// Record updates the events with the rooms states of status, retrieved at the given time
+func (owr *OpenWindowRecorder) Record(status energy.HomeStatus, at time.Time) {
+	for _, room := range status.Rooms {
+		start, open := owr.open[room.ID]
+		switch {
+		case room.OpenWindow && !open:
+			owr.open[room.ID] = at
+		case !room.OpenWindow && open:
+			owr.events = append(owr.events, OpenWindowEvent{RoomID: room.ID, Start: start, End: at})
+			delete(owr.open, room.ID)
+		}
+	}
+}
+
+// Events returns the recorded events (including the windows still open) sorted by start time
+func (owr *OpenWindowRecorder) Events() (events []OpenWindowEvent) {
+	events = make([]OpenWindowEvent, len(owr.events), len(owr.events)+len(owr.open))
+	copy(events, owr.events)
+	for roomID, start := range owr.open {
+		events = append(events, OpenWindowEvent{RoomID: roomID, Start: start})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Start.Equal(events[j].Start) {
+			return events[i].RoomID < events[j].RoomID
+		}
+		return events[i].Start.Before(events[j].Start)
+	})
+	return
+}
@@ -0,0 +1,205 @@
+package energy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/go-querystring/query"
+	"github.com/hekmon/go-netatmo"
+	"github.com/hekmon/go-netatmo/internal/measures"
+)
+
+// MaxMeasurePoints is the maximum number of points the API returns for a single measure request.
+// GetMeasure() and GetRoomMeasure() request several pages transparently for longer ranges.
+const MaxMeasurePoints = 1024
+
+// MeasureScale represents the timelapse between two measurements
+type MeasureScale string
+
+const (
+	// MeasureScaleMax represents every value stored (GetMeasure() only)
+	MeasureScaleMax MeasureScale = "max"
+	// MeasureScale30Min represents a 30 minutes timelapse
+	MeasureScale30Min MeasureScale = "30min"
+	// MeasureScale1Hour represents a 1 hour timelapse
+	MeasureScale1Hour MeasureScale = "1hour"
+	// MeasureScale3Hours represents a 3 hours timelapse
+	MeasureScale3Hours MeasureScale = "3hours"
+	// MeasureScale1Day represents a 1 day timelapse
+	MeasureScale1Day MeasureScale = "1day"
+	// MeasureScale1Week represents a 1 week timelapse
+	MeasureScale1Week MeasureScale = "1week"
+	// MeasureScale1Month represents a 1 month timelapse
+	MeasureScale1Month MeasureScale = "1month"
+)
+
+// MeasureType represents a type of measure which can be requested with GetRoomMeasure() or GetMeasure()
+type MeasureType string
+
+const (
+	// MeasureTypeTemperature represents the room temperature (°C, GetRoomMeasure() only)
+	MeasureTypeTemperature MeasureType = "temperature"
+	// MeasureTypeMinTemp represents the minimum room temperature over the scale (°C, GetRoomMeasure() only)
+	MeasureTypeMinTemp MeasureType = "min_temp"
+	// MeasureTypeMaxTemp represents the maximum room temperature over the scale (°C, GetRoomMeasure() only)
+	MeasureTypeMaxTemp MeasureType = "max_temp"
+	// MeasureTypeDateMinTemp represents the timestamp of the minimum room temperature over the scale (GetRoomMeasure() only)
+	MeasureTypeDateMinTemp MeasureType = "date_min_temp"
+	// MeasureTypeDateMaxTemp represents the timestamp of the maximum room temperature over the scale (GetRoomMeasure() only)
+	MeasureTypeDateMaxTemp MeasureType = "date_max_temp"
+	// MeasureTypeBoilerOn represents the boiler activation state (GetMeasure() only)
+	MeasureTypeBoilerOn MeasureType = "boileron"
+	// MeasureTypeBoilerOff represents the boiler deactivation state (GetMeasure() only)
+	MeasureTypeBoilerOff MeasureType = "boileroff"
+	// MeasureTypeSumBoilerOn represents the time the boiler was on over the scale (seconds, GetMeasure() only)
+	MeasureTypeSumBoilerOn MeasureType = "sum_boiler_on"
+	// MeasureTypeSumBoilerOff represents the time the boiler was off over the scale (seconds, GetMeasure() only)
+	MeasureTypeSumBoilerOff MeasureType = "sum_boiler_off"
+)
+
+// GetRoomMeasureParameters represents the parameters for GetRoomMeasure()
+type GetRoomMeasureParameters struct {
+	HomeID    string        `url:"home_id"`                   // id of the home
+	RoomID    RoomID        `url:"room_id"`                   // id of the room
+	Scale     MeasureScale  `url:"scale"`                     // timelapse between two measurements (max is not supported)
+	Types     []MeasureType `url:"type,comma"`                // types of measures wanted
+	DateBegin time.Time     `url:"date_begin,omitempty,unix"` // starting time (needed for the automatic pagination)
+	DateEnd   time.Time     `url:"date_end,omitempty,unix"`   // ending time
+	Limit     int           `url:"-"`                         // maximum number of points returned (0 for the whole range)
+	Optimize  bool          `url:"optimize"`                  // determines the format of the answer, both are handled transparently
+	RealTime  bool          `url:"real_time,omitempty"`       // if true, timestamps are not offset by scale/2
+}
+
+// GetRoomMeasure retrieves the temperature history of a room. When DateBegin is set, ranges longer than
+// MaxMeasurePoints points are requested by pages: the returned headers are the ones of the last page and
+// the request stats execution time is the sum of all pages.
+// https://dev.netatmo.com/apidocumentation/energy#getroommeasure
+func (c *Client) GetRoomMeasure(ctx context.Context, params GetRoomMeasureParameters) (series MeasureSeries,
+	headers http.Header, rs netatmo.RequestStats, err error) {
+	// verify
+	if params.HomeID == "" {
+		err = errors.New("home ID can not be empty")
+		return
+	}
+	if params.RoomID == "" {
+		err = errors.New("room ID can not be empty")
+		return
+	}
+	if params.Scale == "" || params.Scale == MeasureScaleMax {
+		err = fmt.Errorf("invalid scale: '%s'", params.Scale)
+		return
+	}
+	if len(params.Types) == 0 {
+		err = errors.New("at least one measure type must be requested")
+		return
+	}
+	// prepare parameters
+	urlValues, err := query.Values(params)
+	if err != nil {
+		err = fmt.Errorf("can not convert params as URL values: %w", err)
+		return
+	}
+	// query
+	return c.getMeasurePages(ctx, "/getroommeasure", urlValues, params.Types, params.DateBegin, params.DateEnd, params.Limit)
+}
+
+// GetMeasureParameters represents the parameters for GetMeasure()
+type GetMeasureParameters struct {
+	DeviceID  string        `url:"device_id"`                 // id of the relay
+	ModuleID  string        `url:"module_id"`                 // id of the thermostat
+	Scale     MeasureScale  `url:"scale"`                     // timelapse between two measurements
+	Types     []MeasureType `url:"type,comma"`                // types of measures wanted
+	DateBegin time.Time     `url:"date_begin,omitempty,unix"` // starting time (needed for the automatic pagination)
+	DateEnd   time.Time     `url:"date_end,omitempty,unix"`   // ending time
+	Limit     int           `url:"-"`                         // maximum number of points returned (0 for the whole range)
+	Optimize  bool          `url:"optimize"`                  // determines the format of the answer, both are handled transparently
+	RealTime  bool          `url:"real_time,omitempty"`       // if true, timestamps are not offset by scale/2
+}
+
+// GetMeasure retrieves the boiler history of a thermostat. When DateBegin is set, ranges longer than
+// MaxMeasurePoints points are requested by pages: the returned headers are the ones of the last page and
+// the request stats execution time is the sum of all pages.
+// https://dev.netatmo.com/apidocumentation/energy#getmeasure
+func (c *Client) GetMeasure(ctx context.Context, params GetMeasureParameters) (series MeasureSeries,
+	headers http.Header, rs netatmo.RequestStats, err error) {
+	// verify
+	if params.DeviceID == "" {
+		err = errors.New("device ID can not be empty")
+		return
+	}
+	if params.ModuleID == "" {
+		err = errors.New("module ID can not be empty")
+		return
+	}
+	if params.Scale == "" {
+		err = errors.New("scale can not be empty")
+		return
+	}
+	if len(params.Types) == 0 {
+		err = errors.New("at least one measure type must be requested")
+		return
+	}
+	// prepare parameters
+	urlValues, err := query.Values(params)
+	if err != nil {
+		err = fmt.Errorf("can not convert params as URL values: %w", err)
+		return
+	}
+	// query
+	return c.getMeasurePages(ctx, "/getmeasure", urlValues, params.Types, params.DateBegin, params.DateEnd, params.Limit)
+}
+
+// getMeasurePages requests the measures by pages of MaxMeasurePoints points until the range is covered or limit is reached
+func (c *Client) getMeasurePages(ctx context.Context, endpoint string, urlValues url.Values, types []MeasureType,
+	begin, end time.Time, limit int) (series MeasureSeries, headers http.Header, rs netatmo.RequestStats, err error) {
+	series.Types = types
+	var (
+		body      json.RawMessage
+		page      MeasurePoints
+		pageStats netatmo.RequestStats
+	)
+	for {
+		// prepare page
+		pageSize := MaxMeasurePoints
+		if limit > 0 && limit-len(series.Points) < pageSize {
+			pageSize = limit - len(series.Points)
+		}
+		urlValues.Set("limit", strconv.Itoa(pageSize))
+		if !begin.IsZero() {
+			urlValues.Set("date_begin", strconv.FormatInt(begin.Unix(), 10))
+		}
+		// query
+		if headers, pageStats, err = c.client.ExecuteNetatmoAPIRequest(ctx, "GET", endpoint, urlValues, nil, &body); err != nil {
+			return
+		}
+		pageStats.TimeExec += rs.TimeExec
+		rs = pageStats
+		// parse
+		if page, err = measures.Unmarshal(body, len(types)); err != nil {
+			err = fmt.Errorf("failed to parse the measures: %w", err)
+			return
+		}
+		full := len(page) == pageSize
+		if len(series.Points) > 0 {
+			// drop the points already received, in case date_begin has not been honored
+			for len(page) > 0 && !page[0].Time.After(series.Points[len(series.Points)-1].Time) {
+				page = page[1:]
+			}
+		}
+		series.Points = append(series.Points, page...)
+		// next page ? (stop if the last page did not make any progress)
+		if begin.IsZero() || !full || len(page) == 0 || (limit > 0 && len(series.Points) >= limit) {
+			return
+		}
+		begin = page[len(page)-1].Time.Add(time.Second)
+		if !end.IsZero() && begin.After(end) {
+			return
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package energy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-querystring/query"
+	"github.com/hekmon/go-netatmo"
+)
+
+// GetHomesDataParameters represents the parameters used in GetHomesData()
+type GetHomesDataParameters struct {
+	HomeID       string      `url:"home_id,omitempty"`       // filter by the ID of the home you want
+	GatewayTypes ModuleTypes `url:"gateway_types,omitempty"` // filter by gateway types
+}
+
+// GetHomesData retrieves the user homes and their topology (rooms, modules and schedules).
+// https://dev.netatmo.com/apidocumentation/energy#homesdata
+func (c *Client) GetHomesData(ctx context.Context, params GetHomesDataParameters) (data HomesDataBody,
+	headers http.Header, rs netatmo.RequestStats, err error) {
+	urlValues, err := query.Values(params)
+	if err != nil {
+		err = fmt.Errorf("can not convert params as URL values: %w", err)
+		return
+	}
+	headers, rs, err = c.client.ExecuteNetatmoAPIRequest(ctx, "GET", "/homesdata", urlValues, nil, &data)
+	return
+}
+
+// HomesDataBody represents the body returned by GetHomesData()
+type HomesDataBody struct {
+	Homes []Home `json:"homes"`
+	User  User   `json:"user"`
+}
+
+// Home returns the home matching homeID
+func (hdb HomesDataBody) Home(homeID string) (home Home, found bool) {
+	for _, home = range hdb.Homes {
+		if home.ID == homeID {
+			found = true
+			return
+		}
+	}
+	return Home{}, false
+}
+
+// User contains the user account informations
+type User struct {
+	ID                string `json:"id"`
+	Email             string `json:"email"`
+	Language          string `json:"language"`
+	Locale            string `json:"locale"`
+	FeelLikeAlgorithm int    `json:"feel_like_algorithm"` // 0 for humidex, 1 for heat-index
+	UnitPressure      int    `json:"unit_pressure"`       // 0 for mbar, 1 for inHg, 2 for mmHg
+	UnitSystem        int    `json:"unit_system"`         // 0 for metric, 1 for imperial
+	UnitWind          int    `json:"unit_wind"`           // 0 for kph, 1 for mph, 2 for ms, 3 for beaufort, 4 for knot
+}
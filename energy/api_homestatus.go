@@ -0,0 +1,48 @@
+package energy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-querystring/query"
+	"github.com/hekmon/go-netatmo"
+)
+
+type getHomeStatusParameters struct {
+	HomeID      string      `url:"home_id"`                // the targeted home
+	DeviceTypes ModuleTypes `url:"device_types,omitempty"` // restrict the answer to these devices types
+}
+
+type homeStatusBody struct {
+	Home HomeStatus `json:"home"`
+}
+
+// GetHomeStatus retrieves the current status of a home: rooms temperatures and setpoints, modules states.
+// deviceTypes can be used to restrict the modules returned (nil for all).
+// https://dev.netatmo.com/apidocumentation/energy#homestatus
+func (c *Client) GetHomeStatus(ctx context.Context, homeID string, deviceTypes []ModuleType) (status HomeStatus,
+	headers http.Header, rs netatmo.RequestStats, err error) {
+	// verify
+	if homeID == "" {
+		err = errors.New("home ID can not be empty")
+		return
+	}
+	// prepare parameters
+	urlValues, err := query.Values(getHomeStatusParameters{
+		HomeID:      homeID,
+		DeviceTypes: deviceTypes,
+	})
+	if err != nil {
+		err = fmt.Errorf("can not convert params as URL values: %w", err)
+		return
+	}
+	// query
+	var body homeStatusBody
+	if headers, rs, err = c.client.ExecuteNetatmoAPIRequest(ctx, "GET", "/homestatus", urlValues, nil, &body); err != nil {
+		return
+	}
+	status = body.Home
+	return
+}
@@ -0,0 +1,88 @@
+package energy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/go-querystring/query"
+	"github.com/hekmon/go-netatmo"
+)
+
+const (
+	// MinRoomSetpoint is the minimum manual setpoint temperature accepted by the thermostats and valves (°C)
+	MinRoomSetpoint = 7
+	// MaxRoomSetpoint is the maximum manual setpoint temperature accepted by the thermostats and valves (°C)
+	MaxRoomSetpoint = 30
+)
+
+// SetRoomThermPointParameters represents the parameters for SetRoomThermPoint()
+type SetRoomThermPointParameters struct {
+	HomeID  string       `url:"home_id"`                // id of the home
+	RoomID  RoomID       `url:"room_id"`                // id of the room
+	Mode    SetpointMode `url:"mode"`                   // SetpointModeManual, SetpointModeMax or SetpointModeHome (back to the home mode)
+	Temp    float64      `url:"temp,omitempty"`         // manual mode only: temperature to apply (°C)
+	EndTime time.Time    `url:"endtime,omitempty,unix"` // manual and max modes only: end of the setpoint (home default duration if zero)
+}
+
+// Validate checks the parameters consistency before sending them
+func (srtpp SetRoomThermPointParameters) Validate() error {
+	if srtpp.HomeID == "" {
+		return errors.New("home ID can not be empty")
+	}
+	if srtpp.RoomID == "" {
+		return errors.New("room ID can not be empty")
+	}
+	switch srtpp.Mode {
+	case SetpointModeManual:
+		if srtpp.Temp < MinRoomSetpoint || srtpp.Temp > MaxRoomSetpoint {
+			return fmt.Errorf("manual temperature must be between %d°C and %d°C: %v°C", MinRoomSetpoint, MaxRoomSetpoint, srtpp.Temp)
+		}
+	case SetpointModeMax:
+		if srtpp.Temp != 0 {
+			return fmt.Errorf("temperature can not be set with the %s mode", srtpp.Mode)
+		}
+	case SetpointModeHome:
+		if srtpp.Temp != 0 {
+			return fmt.Errorf("temperature can not be set with the %s mode", srtpp.Mode)
+		}
+		if !srtpp.EndTime.IsZero() {
+			return fmt.Errorf("end time can not be set with the %s mode", srtpp.Mode)
+		}
+	default:
+		return fmt.Errorf("unsupported setpoint mode: %s", string(srtpp.Mode))
+	}
+	if !srtpp.EndTime.IsZero() && !srtpp.EndTime.After(time.Now()) {
+		return fmt.Errorf("end time must be in the future: %v", srtpp.EndTime)
+	}
+	return nil
+}
+
+// SetRoomThermPoint sets a manual temperature to a room, or switches it to the max or home mode.
+// modified is false (and err nil) if the room was already in the requested state.
+// https://dev.netatmo.com/apidocumentation/energy#setroomthermpoint
+func (c *Client) SetRoomThermPoint(ctx context.Context, params SetRoomThermPointParameters) (modified bool,
+	headers http.Header, rs netatmo.RequestStats, err error) {
+	// verify
+	if err = params.Validate(); err != nil {
+		err = fmt.Errorf("invalid parameters: %w", err)
+		return
+	}
+	// prepare parameters
+	urlValues, err := query.Values(params)
+	if err != nil {
+		err = fmt.Errorf("can not convert params as URL values: %w", err)
+		return
+	}
+	// query
+	if headers, rs, err = c.client.ExecuteNetatmoAPIRequest(ctx, "POST", "/setroomthermpoint", urlValues, nil, nil); err != nil {
+		if netatmo.IsNothingToModify(err) {
+			err = nil
+		}
+		return
+	}
+	modified = true
+	return
+}
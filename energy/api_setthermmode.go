@@ -0,0 +1,69 @@
+package energy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/go-querystring/query"
+	"github.com/hekmon/go-netatmo"
+)
+
+// SetThermModeParameters represents the parameters for SetThermMode()
+type SetThermModeParameters struct {
+	HomeID     string    `url:"home_id"`                // id of the home
+	Mode       ThermMode `url:"mode"`                   // heating mode of the home (see ThermMode const values)
+	EndTime    time.Time `url:"endtime,omitempty,unix"` // away and frost guard modes only: end of the mode (no end if zero)
+	ScheduleID string    `url:"schedule_id,omitempty"`  // schedule mode only: switch to this schedule
+}
+
+// Validate checks the parameters consistency before sending them
+func (stmp SetThermModeParameters) Validate() error {
+	if stmp.HomeID == "" {
+		return errors.New("home ID can not be empty")
+	}
+	switch stmp.Mode {
+	case ThermModeSchedule:
+		if !stmp.EndTime.IsZero() {
+			return fmt.Errorf("end time can not be set with the %s mode", stmp.Mode)
+		}
+	case ThermModeAway, ThermModeFrostGuard:
+		if stmp.ScheduleID != "" {
+			return fmt.Errorf("schedule ID can not be set with the %s mode", stmp.Mode)
+		}
+	default:
+		return fmt.Errorf("unsupported therm mode: %s", string(stmp.Mode))
+	}
+	if !stmp.EndTime.IsZero() && !stmp.EndTime.After(time.Now()) {
+		return fmt.Errorf("end time must be in the future: %v", stmp.EndTime)
+	}
+	return nil
+}
+
+// SetThermMode sets the heating mode of a home. modified is false (and err nil) if the home was already in the requested mode.
+// https://dev.netatmo.com/apidocumentation/energy#setthermmode
+func (c *Client) SetThermMode(ctx context.Context, params SetThermModeParameters) (modified bool,
+	headers http.Header, rs netatmo.RequestStats, err error) {
+	// verify
+	if err = params.Validate(); err != nil {
+		err = fmt.Errorf("invalid parameters: %w", err)
+		return
+	}
+	// prepare parameters
+	urlValues, err := query.Values(params)
+	if err != nil {
+		err = fmt.Errorf("can not convert params as URL values: %w", err)
+		return
+	}
+	// query
+	if headers, rs, err = c.client.ExecuteNetatmoAPIRequest(ctx, "POST", "/setthermmode", urlValues, nil, nil); err != nil {
+		if netatmo.IsNothingToModify(err) {
+			err = nil
+		}
+		return
+	}
+	modified = true
+	return
+}
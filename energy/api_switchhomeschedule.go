@@ -0,0 +1,50 @@
+package energy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-querystring/query"
+	"github.com/hekmon/go-netatmo"
+)
+
+type switchHomeScheduleParameters struct {
+	HomeID     string `url:"home_id"`     // the targeted home
+	ScheduleID string `url:"schedule_id"` // the schedule to select
+}
+
+// SwitchHomeSchedule selects the schedule the home should follow. modified is false (and err nil) if the
+// schedule was already selected.
+// https://dev.netatmo.com/apidocumentation/energy#switchhomeschedule
+func (c *Client) SwitchHomeSchedule(ctx context.Context, homeID, scheduleID string) (modified bool,
+	headers http.Header, rs netatmo.RequestStats, err error) {
+	// verify
+	if homeID == "" {
+		err = errors.New("home ID can not be empty")
+		return
+	}
+	if scheduleID == "" {
+		err = errors.New("schedule ID can not be empty")
+		return
+	}
+	// prepare parameters
+	urlValues, err := query.Values(switchHomeScheduleParameters{
+		HomeID:     homeID,
+		ScheduleID: scheduleID,
+	})
+	if err != nil {
+		err = fmt.Errorf("can not convert params as URL values: %w", err)
+		return
+	}
+	// query
+	if headers, rs, err = c.client.ExecuteNetatmoAPIRequest(ctx, "POST", "/switchhomeschedule", urlValues, nil, nil); err != nil {
+		if netatmo.IsNothingToModify(err) {
+			err = nil
+		}
+		return
+	}
+	modified = true
+	return
+}
@@ -0,0 +1,89 @@
+package energy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hekmon/go-netatmo"
+)
+
+type syncHomeScheduleBody struct {
+	HomeID     string             `json:"home_id"`
+	ScheduleID string             `json:"schedule_id"`
+	Name       string             `json:"name,omitempty"`
+	HGTemp     float64            `json:"hg_temp"`
+	AwayTemp   float64            `json:"away_temp"`
+	Timetable  []TimetableEntry   `json:"timetable"`
+	Zones      []syncScheduleZone `json:"zones"`
+}
+
+type syncScheduleZone struct {
+	ID    int        `json:"id"`
+	Name  string     `json:"name,omitempty"`
+	Type  ZoneType   `json:"type"`
+	Rooms []ZoneRoom `json:"rooms"`
+}
+
+// SyncHomeSchedule replaces the timetable, the zones and the away/frost guard temperatures of the schedule
+// (schedule.ID must reference an existing schedule of home h). The schedule is validated against the home
+// before being sent (see Schedule.Validate()). modified is false (and err nil) if the schedule was already identical.
+// https://dev.netatmo.com/apidocumentation/energy#synchomeschedule
+func (c *Client) SyncHomeSchedule(ctx context.Context, h Home, schedule Schedule) (modified bool,
+	headers http.Header, rs netatmo.RequestStats, err error) {
+	// verify
+	if h.ID == "" {
+		err = errors.New("home ID can not be empty")
+		return
+	}
+	if schedule.ID == "" {
+		err = errors.New("schedule ID can not be empty")
+		return
+	}
+	if err = schedule.Validate(h); err != nil {
+		err = fmt.Errorf("invalid schedule: %w", err)
+		return
+	}
+	// prepare body
+	body := syncHomeScheduleBody{
+		HomeID:     h.ID,
+		ScheduleID: schedule.ID,
+		Name:       schedule.Name,
+		HGTemp:     schedule.HGTemp,
+		AwayTemp:   schedule.AwayTemp,
+		Timetable:  schedule.Timetable,
+		Zones:      make([]syncScheduleZone, len(schedule.Zones)),
+	}
+	for index, zone := range schedule.Zones {
+		setpoints := zone.Setpoints()
+		body.Zones[index] = syncScheduleZone{
+			ID:    zone.ID,
+			Name:  zone.Name,
+			Type:  zone.Type,
+			Rooms: make([]ZoneRoom, 0, len(setpoints)),
+		}
+		for _, room := range h.Rooms {
+			body.Zones[index].Rooms = append(body.Zones[index].Rooms, ZoneRoom{
+				ID:                       room.ID,
+				ThermSetpointTemperature: setpoints[room.ID],
+			})
+		}
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		err = fmt.Errorf("can not marshal the schedule as JSON: %w", err)
+		return
+	}
+	// query
+	if headers, rs, err = c.client.ExecuteNetatmoAPIRequest(ctx, "POST", "/synchomeschedule", nil, bytes.NewReader(payload), nil); err != nil {
+		if netatmo.IsNothingToModify(err) {
+			err = nil
+		}
+		return
+	}
+	modified = true
+	return
+}
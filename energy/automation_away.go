@@ -0,0 +1,364 @@
+package energy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAwayPollInterval      = time.Minute
+	defaultAwayDelay             = 15 * time.Minute
+	defaultAwayReturnDelay       = time.Minute
+	defaultAwayMinSwitchInterval = 30 * time.Minute
+	defaultAwayAuditSize         = 1000
+)
+
+// PresenceSource reports if someone is at home (phones on the wifi, alarm state, geofencing, etc...)
+type PresenceSource interface {
+	Present(ctx context.Context) (present bool, err error)
+}
+
+// PresenceFunc allows to use a simple function as a PresenceSource
+type PresenceFunc func(ctx context.Context) (present bool, err error)
+
+// Present implements the PresenceSource interface
+func (pf PresenceFunc) Present(ctx context.Context) (present bool, err error) {
+	return pf(ctx)
+}
+
+// AwayAutomationConfig allows to customize an AwayAutomation. Zero values are replaced by sane defaults.
+type AwayAutomationConfig struct {
+	HomeID            string           // home to manage (mandatory)
+	PollInterval      time.Duration    // delay between two evaluations when started with Run() (default 1m)
+	AwayDelay         time.Duration    // everyone must be gone for this long before switching to away (default 15m)
+	ReturnDelay       time.Duration    // someone must be back for this long before switching back to schedule (default 1m)
+	MinSwitchInterval time.Duration    // minimum delay between two switches made by the automation (default 30m)
+	AuditSize         int              // number of audit entries kept in memory (default 1000)
+	AuditCallback     func(AuditEntry) // if set, called (synchronously) for each new audit entry
+	Now               func() time.Time // clock used by the automation (default time.Now), allows to simulate time in tests
+}
+
+// AuditAction represents the type of an audit entry
+type AuditAction int
+
+const (
+	// AuditActionAway is recorded when the home has been switched to the away mode
+	AuditActionAway AuditAction = iota
+	// AuditActionSchedule is recorded when the home has been switched back to the schedule mode
+	AuditActionSchedule
+	// AuditActionSkipped is recorded when a switch was due but has not been done (manual override, minimum interval, etc...)
+	AuditActionSkipped
+	// AuditActionReleased is recorded when the home mode has been changed by someone else while in away mode:
+	// the automation does not switch it back
+	AuditActionReleased
+	// AuditActionError is recorded when the presence source or an API call has failed
+	AuditActionError
+)
+
+// String implements the https://golang.org/pkg/fmt/#Stringer interface
+func (aa AuditAction) String() string {
+	switch aa {
+	case AuditActionAway:
+		return "away"
+	case AuditActionSchedule:
+		return "schedule"
+	case AuditActionSkipped:
+		return "skipped"
+	case AuditActionReleased:
+		return "released"
+	case AuditActionError:
+		return "error"
+	default:
+		return "<unknown>"
+	}
+}
+
+// GoString implements the https://golang.org/pkg/fmt/#GoStringer interface
+func (aa AuditAction) GoString() string {
+	return fmt.Sprintf("%s (%d)", aa.String(), aa)
+}
+
+// AuditEntry records a decision of an AwayAutomation
+type AuditEntry struct {
+	Time    time.Time
+	Action  AuditAction
+	Present bool   // presence as reported by the source when the decision was made
+	Reason  string // human readable explanation
+	Err     error  // only set for AuditActionError
+}
+
+// String returns a log friendly version of the entry
+func (ae AuditEntry) String() string {
+	line := fmt.Sprintf("%s %s: %s", ae.Time.Format(time.RFC3339), ae.Action, ae.Reason)
+	if ae.Err != nil {
+		line += ": " + ae.Err.Error()
+	}
+	return line
+}
+
+// AwayAutomation switches the home to the away mode when everyone has left and back to the schedule mode on
+// return. Presence changes must be stable for AwayDelay (or ReturnDelay) before acting and two switches are
+// at least MinSwitchInterval apart. Manual overrides always win: no switch is made while a room is in the
+// manual, max or off setpoint mode (see homestatus therm_setpoint_mode), the home is not put away while in
+// frost guard mode, an away mode set by someone else is never switched back, and if the home mode is changed
+// by someone else while away, the automation stops managing it until the next departure. Every decision is
+// recorded in the audit log.
+//
+// The client can be created on any netatmo.AuthenticatedClient implementation and the clock is configurable:
+// combined with Step(), this allows to test the automation with a fake presence source and a stand-in server.
+type AwayAutomation struct {
+	client *Client
+	source PresenceSource
+	conf   AwayAutomationConfig
+	// state
+	initialized   bool
+	present       bool
+	presenceSince time.Time
+	away          bool // home switched to away by the automation
+	foreignAway   bool // home put away by someone else during the current absence: left alone
+	lastSwitch    time.Time
+	lastSkip      string
+	// audit
+	audit     []AuditEntry
+	auditLock sync.Mutex
+}
+
+// NewAwayAutomation returns an automation managing the away mode of a home from the presence reported by source.
+// Call Run() to start it or Step() to drive it manually.
+func (c *Client) NewAwayAutomation(source PresenceSource, conf AwayAutomationConfig) (aa *AwayAutomation, err error) {
+	if conf.HomeID == "" {
+		err = errors.New("home ID can not be empty")
+		return
+	}
+	if source == nil {
+		err = errors.New("presence source can not be nil")
+		return
+	}
+	if conf.PollInterval <= 0 {
+		conf.PollInterval = defaultAwayPollInterval
+	}
+	if conf.AwayDelay <= 0 {
+		conf.AwayDelay = defaultAwayDelay
+	}
+	if conf.ReturnDelay <= 0 {
+		conf.ReturnDelay = defaultAwayReturnDelay
+	}
+	if conf.MinSwitchInterval <= 0 {
+		conf.MinSwitchInterval = defaultAwayMinSwitchInterval
+	}
+	if conf.AuditSize <= 0 {
+		conf.AuditSize = defaultAwayAuditSize
+	}
+	if conf.Now == nil {
+		conf.Now = time.Now
+	}
+	aa = &AwayAutomation{
+		client: c,
+		source: source,
+		conf:   conf,
+	}
+	return
+}
+
+// Audit returns a copy of the audit log, oldest entry first. It is safe to call it while the automation runs.
+func (aa *AwayAutomation) Audit() (entries []AuditEntry) {
+	aa.auditLock.Lock()
+	defer aa.auditLock.Unlock()
+	entries = make([]AuditEntry, len(aa.audit))
+	copy(entries, aa.audit)
+	return
+}
+
+// Run evaluates the presence every PollInterval until ctx is cancelled. It blocks and must only be called once.
+func (aa *AwayAutomation) Run(ctx context.Context) {
+	ticker := time.NewTicker(aa.conf.PollInterval)
+	defer ticker.Stop()
+	aa.Step(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			aa.Step(ctx)
+		}
+	}
+}
+
+// Step evaluates the presence once and switches the home mode if needed. It must not be called concurrently
+// (nor while Run() is running).
+func (aa *AwayAutomation) Step(ctx context.Context) {
+	now := aa.conf.Now()
+	present, err := aa.source.Present(ctx)
+	if err != nil {
+		aa.record(AuditEntry{
+			Time:    now,
+			Action:  AuditActionError,
+			Present: aa.present,
+			Reason:  "can not get the presence",
+			Err:     err,
+		})
+		return
+	}
+	if !aa.initialized || present != aa.present {
+		aa.initialized = true
+		aa.present = present
+		aa.presenceSince = now
+		aa.foreignAway = false
+	}
+	stable := now.Sub(aa.presenceSince)
+	switch {
+	case !present && !aa.away && !aa.foreignAway && stable >= aa.conf.AwayDelay:
+		aa.switchMode(ctx, now, ThermModeAway, fmt.Sprintf("nobody at home for %s", stable.Round(time.Second)))
+	case present && aa.away && stable >= aa.conf.ReturnDelay:
+		aa.switchMode(ctx, now, ThermModeSchedule, fmt.Sprintf("presence detected for %s", stable.Round(time.Second)))
+	default:
+		aa.lastSkip = ""
+	}
+}
+
+func (aa *AwayAutomation) switchMode(ctx context.Context, now time.Time, mode ThermMode, reason string) {
+	// minimum interval
+	if !aa.lastSwitch.IsZero() && now.Sub(aa.lastSwitch) < aa.conf.MinSwitchInterval {
+		aa.skip(now, fmt.Sprintf("switch to %s delayed: last switch was less than %s ago", mode, aa.conf.MinSwitchInterval))
+		return
+	}
+	// check the rooms states
+	status, _, _, err := aa.client.GetHomeStatus(ctx, aa.conf.HomeID, nil)
+	if err != nil {
+		aa.record(AuditEntry{
+			Time:    now,
+			Action:  AuditActionError,
+			Present: aa.present,
+			Reason:  "can not get the home status",
+			Err:     err,
+		})
+		return
+	}
+	var (
+		overridden      []string
+		awayRooms       int
+		frostGuardRooms int
+	)
+	for _, room := range status.Rooms {
+		switch room.SetpointMode {
+		case SetpointModeManual, SetpointModeMax, SetpointModeOff:
+			overridden = append(overridden, string(room.ID))
+		case SetpointModeAway:
+			awayRooms++
+		case SetpointModeFrostGuard:
+			frostGuardRooms++
+		}
+	}
+	if len(overridden) > 0 {
+		aa.skip(now, fmt.Sprintf("switch to %s skipped: manual override in room(s) %s", mode, strings.Join(overridden, ", ")))
+		return
+	}
+	switch mode {
+	case ThermModeAway:
+		if frostGuardRooms > 0 {
+			aa.skip(now, "switch to away skipped: home is in frost guard mode")
+			return
+		}
+		if awayRooms > 0 && awayRooms == len(status.Rooms) {
+			aa.leaveForeignAway(now)
+			return
+		}
+	case ThermModeSchedule:
+		if awayRooms == 0 {
+			aa.release(now)
+			return
+		}
+	}
+	// switch
+	modified, _, _, err := aa.client.SetThermMode(ctx, SetThermModeParameters{
+		HomeID: aa.conf.HomeID,
+		Mode:   mode,
+	})
+	if err != nil {
+		aa.record(AuditEntry{
+			Time:    now,
+			Action:  AuditActionError,
+			Present: aa.present,
+			Reason:  fmt.Sprintf("can not switch to %s", mode),
+			Err:     err,
+		})
+		return
+	}
+	if !modified {
+		// the home was already in the requested mode: someone else has set it
+		if mode == ThermModeAway {
+			aa.leaveForeignAway(now)
+		} else {
+			aa.release(now)
+		}
+		return
+	}
+	aa.away = mode == ThermModeAway
+	aa.lastSwitch = now
+	aa.lastSkip = ""
+	entry := AuditEntry{
+		Time:    now,
+		Action:  AuditActionSchedule,
+		Present: aa.present,
+		Reason:  reason,
+	}
+	if aa.away {
+		entry.Action = AuditActionAway
+	}
+	aa.record(entry)
+}
+
+// leaveForeignAway records that the home has been put away by someone else: the automation does not take
+// ownership of it (and will not switch it back to schedule) until the presence changes
+func (aa *AwayAutomation) leaveForeignAway(now time.Time) {
+	aa.foreignAway = true
+	aa.lastSkip = ""
+	aa.record(AuditEntry{
+		Time:    now,
+		Action:  AuditActionSkipped,
+		Present: aa.present,
+		Reason:  "switch to away skipped: home has already been put in away mode by someone else",
+	})
+}
+
+// release records that the home mode has been changed by someone else while away: it is not managed anymore
+func (aa *AwayAutomation) release(now time.Time) {
+	aa.away = false
+	aa.lastSkip = ""
+	aa.record(AuditEntry{
+		Time:    now,
+		Action:  AuditActionReleased,
+		Present: aa.present,
+		Reason:  "home is not in away mode anymore: mode has been changed by someone else",
+	})
+}
+
+// skip records a skipped switch, unless the same reason has just been recorded
+func (aa *AwayAutomation) skip(now time.Time, reason string) {
+	if reason == aa.lastSkip {
+		return
+	}
+	aa.lastSkip = reason
+	aa.record(AuditEntry{
+		Time:    now,
+		Action:  AuditActionSkipped,
+		Present: aa.present,
+		Reason:  reason,
+	})
+}
+
+func (aa *AwayAutomation) record(entry AuditEntry) {
+	aa.auditLock.Lock()
+	aa.audit = append(aa.audit, entry)
+	if overflow := len(aa.audit) - aa.conf.AuditSize; overflow > 0 {
+		aa.audit = append(aa.audit[:0], aa.audit[overflow:]...)
+	}
+	aa.auditLock.Unlock()
+	if aa.conf.AuditCallback != nil {
+		aa.conf.AuditCallback(entry)
+	}
+}
@@ -0,0 +1,421 @@
+package energy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hekmon/go-netatmo"
+	"golang.org/x/oauth2"
+)
+
+// stubHome is a stand-in for the Netatmo API serving homestatus and setthermmode for a single home
+type stubHome struct {
+	rooms       map[RoomID]SetpointMode
+	failStatus  bool
+	racedAway   bool        // the home is put away by someone else right before setthermmode is received
+	thermModes  []ThermMode // setthermmode calls received
+	statusCalls int
+}
+
+func newStubHome(mode SetpointMode) *stubHome {
+	return &stubHome{
+		rooms: map[RoomID]SetpointMode{
+			"1": mode,
+			"2": mode,
+		},
+	}
+}
+
+// setHomeMode changes the mode of every room, as a home mode change made from the Netatmo app would
+func (sh *stubHome) setHomeMode(mode SetpointMode) {
+	for roomID := range sh.rooms {
+		sh.rooms[roomID] = mode
+	}
+}
+
+func (sh *stubHome) ExecuteNetatmoAPIRequest(ctx context.Context, method, endpoint string, urlValues url.Values,
+	body io.Reader, destination interface{}) (headers http.Header, rs netatmo.RequestStats, err error) {
+	switch endpoint {
+	case "/homestatus":
+		sh.statusCalls++
+		if sh.failStatus {
+			err = netatmo.HTTPStatusGenericError{HTTPCode: http.StatusInternalServerError, NetatmoCode: 1, Message: "unavailable"}
+			return
+		}
+		rooms := make([]string, 0, len(sh.rooms))
+		for _, roomID := range []RoomID{"1", "2"} {
+			rooms = append(rooms, fmt.Sprintf(`{"id":"%s","reachable":true,"therm_setpoint_mode":"%s"}`, roomID, string(sh.rooms[roomID])))
+		}
+		payload := fmt.Sprintf(`{"home":{"id":"%s","rooms":[%s]}}`, urlValues.Get("home_id"), strings.Join(rooms, ","))
+		err = json.Unmarshal([]byte(payload), destination)
+	case "/setthermmode":
+		if method != http.MethodPost {
+			err = fmt.Errorf("unexpected method %s", method)
+			return
+		}
+		mode := ThermMode(urlValues.Get("mode"))
+		sh.thermModes = append(sh.thermModes, mode)
+		if sh.racedAway {
+			sh.setHomeMode(SetpointModeAway)
+		}
+		target := SetpointMode(mode)
+		modified := false
+		for roomID, current := range sh.rooms {
+			if current != target {
+				sh.rooms[roomID] = target
+				modified = true
+			}
+		}
+		if !modified {
+			err = netatmo.HTTPStatusOKErrors{{Code: netatmo.ErrorCodeNothingToModify}}
+		}
+	default:
+		err = fmt.Errorf("unexpected endpoint %s", endpoint)
+	}
+	return
+}
+
+func (sh *stubHome) GetTokens() oauth2.Token {
+	return oauth2.Token{}
+}
+
+// fakePresence is a presence source whose answer is set by the test
+type fakePresence struct {
+	present bool
+	err     error
+}
+
+func (fp *fakePresence) Present(ctx context.Context) (bool, error) {
+	return fp.present, fp.err
+}
+
+// awayTest drives an AwayAutomation with a simulated clock
+type awayTest struct {
+	t        *testing.T
+	home     *stubHome
+	presence *fakePresence
+	now      time.Time
+	aa       *AwayAutomation
+}
+
+func newAwayTest(t *testing.T, home *stubHome, conf AwayAutomationConfig) *awayTest {
+	at := &awayTest{
+		t:        t,
+		home:     home,
+		presence: &fakePresence{present: true},
+		now:      time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC),
+	}
+	conf.HomeID = "home"
+	conf.Now = func() time.Time { return at.now }
+	var err error
+	if at.aa, err = New(home).NewAwayAutomation(at.presence, conf); err != nil {
+		t.Fatalf("can not create the automation: %s", err)
+	}
+	return at
+}
+
+// run steps the automation every minute for d
+func (at *awayTest) run(d time.Duration) {
+	for end := at.now.Add(d); at.now.Before(end); at.now = at.now.Add(time.Minute) {
+		at.aa.Step(context.Background())
+	}
+}
+
+func (at *awayTest) expectModes(modes ...ThermMode) {
+	at.t.Helper()
+	if fmt.Sprint(at.home.thermModes) != fmt.Sprint(modes) {
+		at.t.Fatalf("expected setthermmode calls %v, got %v", modes, at.home.thermModes)
+	}
+}
+
+func (at *awayTest) expectAudit(actions ...AuditAction) {
+	at.t.Helper()
+	entries := at.aa.Audit()
+	got := make([]AuditAction, len(entries))
+	for index, entry := range entries {
+		got[index] = entry.Action
+	}
+	if fmt.Sprint(got) != fmt.Sprint(actions) {
+		at.t.Fatalf("expected audit actions %v, got %v:\n%s", actions, got, auditLog(entries))
+	}
+}
+
+func auditLog(entries []AuditEntry) string {
+	lines := make([]string, len(entries))
+	for index, entry := range entries {
+		lines[index] = entry.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestAwayAutomationHysteresis(t *testing.T) {
+	at := newAwayTest(t, newStubHome(SetpointModeSchedule), AwayAutomationConfig{
+		AwayDelay:         10 * time.Minute,
+		ReturnDelay:       3 * time.Minute,
+		MinSwitchInterval: time.Minute,
+	})
+	at.run(5 * time.Minute)
+	at.expectModes()
+	// short absences are ignored
+	at.presence.present = false
+	at.run(9 * time.Minute)
+	at.presence.present = true
+	at.run(5 * time.Minute)
+	at.expectModes()
+	// long enough absence
+	at.presence.present = false
+	at.run(10 * time.Minute)
+	at.expectModes()
+	at.run(time.Minute)
+	at.expectModes(ThermModeAway)
+	// short returns are ignored
+	at.presence.present = true
+	at.run(3 * time.Minute)
+	at.presence.present = false
+	at.run(30 * time.Minute)
+	at.expectModes(ThermModeAway)
+	// long enough return
+	at.presence.present = true
+	at.run(4 * time.Minute)
+	at.expectModes(ThermModeAway, ThermModeSchedule)
+	at.expectAudit(AuditActionAway, AuditActionSchedule)
+	entries := at.aa.Audit()
+	if entries[0].Present || !entries[1].Present {
+		t.Errorf("unexpected presence recorded in the audit log:\n%s", auditLog(entries))
+	}
+	if entries[0].Reason != "nobody at home for 10m0s" || entries[1].Reason != "presence detected for 3m0s" {
+		t.Errorf("unexpected reasons in the audit log:\n%s", auditLog(entries))
+	}
+}
+
+func TestAwayAutomationMinSwitchInterval(t *testing.T) {
+	at := newAwayTest(t, newStubHome(SetpointModeSchedule), AwayAutomationConfig{
+		AwayDelay:         5 * time.Minute,
+		ReturnDelay:       time.Minute,
+		MinSwitchInterval: 30 * time.Minute,
+	})
+	at.presence.present = false
+	at.run(6 * time.Minute)
+	at.expectModes(ThermModeAway)
+	switchTime := at.aa.Audit()[0].Time
+	// back quickly: the switch is delayed (and only recorded once)
+	at.presence.present = true
+	at.run(20 * time.Minute)
+	at.expectModes(ThermModeAway)
+	at.expectAudit(AuditActionAway, AuditActionSkipped)
+	at.run(10 * time.Minute)
+	at.expectModes(ThermModeAway, ThermModeSchedule)
+	at.expectAudit(AuditActionAway, AuditActionSkipped, AuditActionSchedule)
+	if elapsed := at.aa.Audit()[2].Time.Sub(switchTime); elapsed != 30*time.Minute {
+		t.Errorf("expected the second switch 30m after the first one, got %s", elapsed)
+	}
+}
+
+func TestAwayAutomationManualOverride(t *testing.T) {
+	home := newStubHome(SetpointModeSchedule)
+	home.rooms["2"] = SetpointModeManual
+	at := newAwayTest(t, home, AwayAutomationConfig{
+		AwayDelay:   5 * time.Minute,
+		ReturnDelay: time.Minute,
+	})
+	at.presence.present = false
+	at.run(20 * time.Minute)
+	at.expectModes()
+	at.expectAudit(AuditActionSkipped)
+	if reason := at.aa.Audit()[0].Reason; !strings.Contains(reason, "manual override in room(s) 2") {
+		t.Errorf("unexpected skip reason: %s", reason)
+	}
+	// the override ends
+	home.rooms["2"] = SetpointModeSchedule
+	at.run(time.Minute)
+	at.expectModes(ThermModeAway)
+	at.expectAudit(AuditActionSkipped, AuditActionAway)
+}
+
+func TestAwayAutomationManualOverrideWhileAway(t *testing.T) {
+	home := newStubHome(SetpointModeSchedule)
+	at := newAwayTest(t, home, AwayAutomationConfig{
+		AwayDelay:         5 * time.Minute,
+		ReturnDelay:       time.Minute,
+		MinSwitchInterval: time.Minute,
+	})
+	at.presence.present = false
+	at.run(6 * time.Minute)
+	at.expectModes(ThermModeAway)
+	// someone boosts a room remotely: switching back to schedule would cancel it
+	home.rooms["1"] = SetpointModeMax
+	at.presence.present = true
+	at.run(10 * time.Minute)
+	at.expectModes(ThermModeAway)
+	at.expectAudit(AuditActionAway, AuditActionSkipped)
+	home.rooms["1"] = SetpointModeAway
+	at.run(time.Minute)
+	at.expectModes(ThermModeAway, ThermModeSchedule)
+}
+
+func TestAwayAutomationForeignAway(t *testing.T) {
+	home := newStubHome(SetpointModeAway)
+	at := newAwayTest(t, home, AwayAutomationConfig{
+		AwayDelay:   5 * time.Minute,
+		ReturnDelay: time.Minute,
+	})
+	at.presence.present = false
+	at.run(30 * time.Minute)
+	at.expectModes()
+	at.expectAudit(AuditActionSkipped)
+	if home.statusCalls != 1 {
+		t.Errorf("the home status has been requested %d times for a single absence", home.statusCalls)
+	}
+	// the away mode set by someone else is never switched back
+	at.presence.present = true
+	at.run(time.Hour)
+	at.expectModes()
+	at.expectAudit(AuditActionSkipped)
+}
+
+func TestAwayAutomationNotModified(t *testing.T) {
+	home := newStubHome(SetpointModeSchedule)
+	home.racedAway = true
+	at := newAwayTest(t, home, AwayAutomationConfig{
+		AwayDelay:   5 * time.Minute,
+		ReturnDelay: time.Minute,
+	})
+	at.presence.present = false
+	at.run(6 * time.Minute)
+	at.expectModes(ThermModeAway)
+	at.expectAudit(AuditActionSkipped)
+	if at.aa.away || !at.aa.lastSwitch.IsZero() {
+		t.Fatal("the automation has taken ownership of an away mode it did not set")
+	}
+	// no retry during the same absence and the away mode is left untouched on return
+	at.run(time.Hour)
+	at.presence.present = true
+	at.run(time.Hour)
+	at.expectModes(ThermModeAway)
+	at.expectAudit(AuditActionSkipped)
+}
+
+func TestAwayAutomationRelease(t *testing.T) {
+	home := newStubHome(SetpointModeSchedule)
+	at := newAwayTest(t, home, AwayAutomationConfig{
+		AwayDelay:         5 * time.Minute,
+		ReturnDelay:       time.Minute,
+		MinSwitchInterval: time.Minute,
+	})
+	at.presence.present = false
+	at.run(6 * time.Minute)
+	at.expectModes(ThermModeAway)
+	// someone switches the home to frost guard while away
+	home.setHomeMode(SetpointModeFrostGuard)
+	at.presence.present = true
+	at.run(10 * time.Minute)
+	at.expectModes(ThermModeAway)
+	at.expectAudit(AuditActionAway, AuditActionReleased)
+	// next departure: frost guard is kept
+	at.presence.present = false
+	at.run(10 * time.Minute)
+	at.expectModes(ThermModeAway)
+	at.expectAudit(AuditActionAway, AuditActionReleased, AuditActionSkipped)
+	// frost guard ended from the app: the automation manages the home again
+	home.setHomeMode(SetpointModeSchedule)
+	at.run(time.Minute)
+	at.expectModes(ThermModeAway, ThermModeAway)
+}
+
+func TestAwayAutomationFrostGuard(t *testing.T) {
+	for _, spelling := range []SetpointMode{SetpointModeFrostGuard, "frost_guard"} {
+		home := newStubHome(spelling)
+		at := newAwayTest(t, home, AwayAutomationConfig{
+			AwayDelay:   5 * time.Minute,
+			ReturnDelay: time.Minute,
+		})
+		at.presence.present = false
+		at.run(10 * time.Minute)
+		at.expectModes()
+		at.expectAudit(AuditActionSkipped)
+		if reason := at.aa.Audit()[0].Reason; reason != "switch to away skipped: home is in frost guard mode" {
+			t.Errorf("%s: unexpected skip reason: %s", spelling, reason)
+		}
+	}
+}
+
+func TestAwayAutomationErrors(t *testing.T) {
+	home := newStubHome(SetpointModeSchedule)
+	at := newAwayTest(t, home, AwayAutomationConfig{
+		AwayDelay:   5 * time.Minute,
+		ReturnDelay: time.Minute,
+	})
+	// presence source failures do not change the state
+	at.presence.err = errors.New("router unreachable")
+	at.run(10 * time.Minute)
+	at.expectModes()
+	entries := at.aa.Audit()
+	if len(entries) != 10 || entries[0].Action != AuditActionError || !strings.Contains(entries[0].String(), "router unreachable") {
+		t.Fatalf("unexpected audit log:\n%s", auditLog(entries))
+	}
+	// API failures are retried on the next step
+	at.presence.err = nil
+	at.presence.present = false
+	home.failStatus = true
+	at.run(6 * time.Minute)
+	at.expectModes()
+	if last := at.aa.Audit()[len(at.aa.Audit())-1]; last.Action != AuditActionError || last.Err == nil {
+		t.Fatalf("expected an API error entry, got %s", last)
+	}
+	home.failStatus = false
+	at.run(time.Minute)
+	at.expectModes(ThermModeAway)
+}
+
+func TestAwayAutomationAudit(t *testing.T) {
+	var received []AuditEntry
+	at := newAwayTest(t, newStubHome(SetpointModeSchedule), AwayAutomationConfig{
+		AuditSize:     3,
+		AuditCallback: func(entry AuditEntry) { received = append(received, entry) },
+	})
+	at.presence.err = errors.New("no presence")
+	at.run(5 * time.Minute)
+	if len(received) != 5 {
+		t.Fatalf("expected 5 entries sent to the callback, got %d", len(received))
+	}
+	entries := at.aa.Audit()
+	if len(entries) != 3 || !entries[0].Time.Equal(received[2].Time) || !entries[2].Time.Equal(received[4].Time) {
+		t.Fatalf("expected the 3 last entries to be kept, got:\n%s", auditLog(entries))
+	}
+}
+
+func TestNewAwayAutomation(t *testing.T) {
+	client := New(newStubHome(SetpointModeSchedule))
+	if _, err := client.NewAwayAutomation(&fakePresence{}, AwayAutomationConfig{}); err == nil {
+		t.Error("an automation without home ID has been created")
+	}
+	if _, err := client.NewAwayAutomation(nil, AwayAutomationConfig{HomeID: "home"}); err == nil {
+		t.Error("an automation without presence source has been created")
+	}
+	aa, err := client.NewAwayAutomation(PresenceFunc(func(context.Context) (bool, error) { return true, nil }),
+		AwayAutomationConfig{HomeID: "home", AwayDelay: -time.Minute})
+	if err != nil {
+		t.Fatalf("can not create the automation: %s", err)
+	}
+	if aa.conf.AwayDelay != defaultAwayDelay || aa.conf.PollInterval != defaultAwayPollInterval ||
+		aa.conf.MinSwitchInterval != defaultAwayMinSwitchInterval || aa.conf.Now == nil {
+		t.Errorf("defaults not applied: %+v", aa.conf)
+	}
+}
+
+func TestAuditActionString(t *testing.T) {
+	if got := fmt.Sprintf("%#v", AuditActionReleased); got != "released (3)" {
+		t.Errorf("unexpected GoString: %s", got)
+	}
+	if got := AuditAction(42).String(); got != "<unknown>" {
+		t.Errorf("unexpected String for an unknown action: %s", got)
+	}
+}
@@ -0,0 +1,19 @@
+package energy
+
+import "github.com/hekmon/go-netatmo"
+
+/*
+	https://dev.netatmo.com/apidocumentation/energy
+*/
+
+// Client holds the logic to perform energy api calls
+type Client struct {
+	client netatmo.AuthenticatedClient
+}
+
+// New returns an energy api capable client
+func New(client netatmo.AuthenticatedClient) *Client {
+	return &Client{
+		client: client,
+	}
+}
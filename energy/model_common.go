@@ -0,0 +1,188 @@
+package energy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ModuleType represents the type of an energy module
+type ModuleType string
+
+const (
+	// ModuleTypeRelay represents the relay (gateway) plugged to the boiler
+	ModuleTypeRelay ModuleType = "NAPlug"
+	// ModuleTypeThermostat represents the smart thermostat
+	ModuleTypeThermostat ModuleType = "NATherm1"
+	// ModuleTypeValve represents a smart radiator valve
+	ModuleTypeValve ModuleType = "NRV"
+)
+
+// String implements the https://golang.org/pkg/fmt/#Stringer interface
+func (mt ModuleType) String() string {
+	switch mt {
+	case ModuleTypeRelay:
+		return "relay"
+	case ModuleTypeThermostat:
+		return "thermostat"
+	case ModuleTypeValve:
+		return "valve"
+	default:
+		return "<unknown>"
+	}
+}
+
+// GoString implements the https://golang.org/pkg/fmt/#GoStringer interface
+func (mt ModuleType) GoString() string {
+	return fmt.Sprintf("%s (%s)", mt.String(), string(mt))
+}
+
+// ModuleTypes represents a list of module types used as a request parameter
+type ModuleTypes []ModuleType
+
+// EncodeValues allows go-querystring to encode the module types with their API values
+// (implements https://pkg.go.dev/github.com/google/go-querystring/query#Encoder)
+func (mts ModuleTypes) EncodeValues(key string, v *url.Values) error {
+	if len(mts) == 0 {
+		return nil
+	}
+	values := make([]string, len(mts))
+	for index, mt := range mts {
+		values[index] = string(mt)
+	}
+	v.Set(key, strings.Join(values, ","))
+	return nil
+}
+
+// ThermMode represents the heating mode of a home
+type ThermMode string
+
+const (
+	// ThermModeSchedule follows the selected schedule
+	ThermModeSchedule ThermMode = "schedule"
+	// ThermModeAway uses the away temperature of the schedule
+	ThermModeAway ThermMode = "away"
+	// ThermModeFrostGuard uses the frost guard temperature of the schedule (for long departures)
+	ThermModeFrostGuard ThermMode = "hg"
+)
+
+// String implements the https://golang.org/pkg/fmt/#Stringer interface
+func (tm ThermMode) String() string {
+	switch tm {
+	case ThermModeSchedule:
+		return "schedule"
+	case ThermModeAway:
+		return "away"
+	case ThermModeFrostGuard:
+		return "frost guard"
+	default:
+		return "<unknown>"
+	}
+}
+
+// GoString implements the https://golang.org/pkg/fmt/#GoStringer interface
+func (tm ThermMode) GoString() string {
+	return fmt.Sprintf("%s (%s)", tm.String(), string(tm))
+}
+
+// EncodeValues allows go-querystring to encode the mode with its API value
+// (implements https://pkg.go.dev/github.com/google/go-querystring/query#Encoder)
+func (tm ThermMode) EncodeValues(key string, v *url.Values) error {
+	if tm != "" {
+		v.Set(key, string(tm))
+	}
+	return nil
+}
+
+// UnmarshalText allows to decode the different spellings used by the API for the same mode
+// (implements https://pkg.go.dev/encoding#TextUnmarshaler)
+func (tm *ThermMode) UnmarshalText(text []byte) error {
+	switch mode := string(text); mode {
+	case "frost_guard":
+		*tm = ThermModeFrostGuard
+	default:
+		*tm = ThermMode(mode)
+	}
+	return nil
+}
+
+// ZoneType represents the type of a schedule zone
+type ZoneType int
+
+const (
+	// ZoneTypeDay represents the day zone
+	ZoneTypeDay ZoneType = 0
+	// ZoneTypeNight represents the night zone
+	ZoneTypeNight ZoneType = 1
+	// ZoneTypeAway represents the away zone
+	ZoneTypeAway ZoneType = 2
+	// ZoneTypeFrostGuard represents the frost guard zone
+	ZoneTypeFrostGuard ZoneType = 3
+	// ZoneTypeCustom represents a user defined zone
+	ZoneTypeCustom ZoneType = 4
+	// ZoneTypeEco represents the eco zone
+	ZoneTypeEco ZoneType = 5
+	// ZoneTypeComfort represents the comfort zone
+	ZoneTypeComfort ZoneType = 8
+)
+
+// String implements the https://golang.org/pkg/fmt/#Stringer interface
+func (zt ZoneType) String() string {
+	switch zt {
+	case ZoneTypeDay:
+		return "day"
+	case ZoneTypeNight:
+		return "night"
+	case ZoneTypeAway:
+		return "away"
+	case ZoneTypeFrostGuard:
+		return "frost guard"
+	case ZoneTypeCustom:
+		return "custom"
+	case ZoneTypeEco:
+		return "eco"
+	case ZoneTypeComfort:
+		return "comfort"
+	default:
+		return "<unknown>"
+	}
+}
+
+// GoString implements the https://golang.org/pkg/fmt/#GoStringer interface
+func (zt ZoneType) GoString() string {
+	return fmt.Sprintf("%s (%d)", zt.String(), zt)
+}
+
+// RoomID represents the ID of a room. The API sends it either as a number or as a string depending on the endpoint.
+type RoomID string
+
+// UnmarshalJSON accepts both the number and the string representations
+func (rid *RoomID) UnmarshalJSON(data []byte) (err error) {
+	var id string
+	if len(data) > 0 && data[0] == '"' {
+		err = json.Unmarshal(data, &id)
+	} else {
+		var number json.Number
+		err = json.Unmarshal(data, &number)
+		id = number.String()
+	}
+	if err != nil {
+		return fmt.Errorf("can not unmarshal room ID: %w", err)
+	}
+	*rid = RoomID(id)
+	return
+}
+
+/*
+	JSON helpers
+*/
+
+// timeFromUnix converts a Netatmo payload timestamp to a time. A missing (0) timestamp gives the zero time.
+func timeFromUnix(timestamp int64) time.Time {
+	if timestamp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(timestamp, 0)
+}
@@ -0,0 +1,205 @@
+package energy
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Home contains the topology and the static informations of a home
+type Home struct {
+	ID                           string         `json:"id"`          // id of the home
+	Name                         string         `json:"name"`        // name of the home
+	Altitude                     int            `json:"altitude"`    // altitude of the home
+	Coordinates                  []float64      `json:"coordinates"` // Long, Lat
+	Country                      string         `json:"country"`     // country of the home
+	Timezone                     *time.Location `json:"-"`           // time zone of the home
+	Rooms                        []Room         `json:"rooms"`       // rooms of the home
+	Modules                      []Module       `json:"modules"`     // relays, thermostats and valves of the home
+	Schedules                    []Schedule     `json:"schedules"`   // heating schedules of the home
+	ThermMode                    ThermMode      `json:"therm_mode"`  // current heating mode of the home
+	ThermModeEndTime             time.Time      `json:"-"`           // end of the current away or frost guard mode (zero if not set)
+	ThermSetPointDefaultDuration time.Duration  `json:"-"`           // default duration of a manual setpoint
+}
+
+// UnmarshalJSON allows to automatically convert data to go types
+func (h *Home) UnmarshalJSON(data []byte) (err error) {
+	// Add tmp type
+	type OriginalUnmarshal Home
+	tmp := struct {
+		Timezone                     string `json:"timezone"`                         // time zone of the home
+		ThermModeEndTime             int64  `json:"therm_mode_endtime"`               // end of the current heating mode
+		ThermSetPointDefaultDuration int64  `json:"therm_set_point_default_duration"` // in minutes (documented key)
+		ThermSetpointDefaultDuration int64  `json:"therm_setpoint_default_duration"`  // in minutes (key actually sent by the API)
+		*OriginalUnmarshal
+	}{
+		OriginalUnmarshal: (*OriginalUnmarshal)(h),
+	}
+	// Unmarshall into the tmp fields
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		err = fmt.Errorf("failed to unmarshal data to the temporary Home struct: %w", err)
+		return
+	}
+	// Convert
+	if tmp.Timezone != "" {
+		if h.Timezone, err = time.LoadLocation(tmp.Timezone); err != nil {
+			return fmt.Errorf("can not parse the Timezone: %w", err)
+		}
+	}
+	h.ThermModeEndTime = timeFromUnix(tmp.ThermModeEndTime)
+	if tmp.ThermSetPointDefaultDuration == 0 {
+		tmp.ThermSetPointDefaultDuration = tmp.ThermSetpointDefaultDuration
+	}
+	h.ThermSetPointDefaultDuration = time.Duration(tmp.ThermSetPointDefaultDuration) * time.Minute
+	return
+}
+
+// Room returns the room matching roomID
+func (h Home) Room(roomID RoomID) (room Room, found bool) {
+	for _, room = range h.Rooms {
+		if room.ID == roomID {
+			found = true
+			return
+		}
+	}
+	return Room{}, false
+}
+
+// Module returns the module matching moduleID
+func (h Home) Module(moduleID string) (module Module, found bool) {
+	for _, module = range h.Modules {
+		if module.ID == moduleID {
+			found = true
+			return
+		}
+	}
+	return Module{}, false
+}
+
+// Schedule returns the schedule matching scheduleID
+func (h Home) Schedule(scheduleID string) (schedule Schedule, found bool) {
+	for _, schedule = range h.Schedules {
+		if schedule.ID == scheduleID {
+			found = true
+			return
+		}
+	}
+	return Schedule{}, false
+}
+
+// SelectedSchedule returns the schedule currently selected for the home
+func (h Home) SelectedSchedule() (schedule Schedule, found bool) {
+	for _, schedule = range h.Schedules {
+		if schedule.Selected {
+			found = true
+			return
+		}
+	}
+	return Schedule{}, false
+}
+
+// Room represents a room of a home
+type Room struct {
+	ID        RoomID   `json:"id"`         // id of the room
+	Name      string   `json:"name"`       // name of the room
+	Type      string   `json:"type"`       // type of the room (kitchen, bedroom, etc...)
+	ModuleIDs []string `json:"module_ids"` // modules associated to this room
+}
+
+// Module represents a relay, a thermostat or a valve. Fields are populated depending on the module type.
+type Module struct {
+	ID             string     `json:"id"`              // id of the module (MAC address)
+	Type           ModuleType `json:"type"`            // type of the module (see ModuleType const values)
+	Name           string     `json:"name"`            // name of the module
+	SetupDate      time.Time  `json:"-"`               // date the module was setup
+	RoomID         RoomID     `json:"room_id"`         // thermostats and valves: room where the module is placed
+	Bridge         string     `json:"bridge"`          // thermostats and valves: id of the relay the module is connected to
+	ModulesBridged []string   `json:"modules_bridged"` // relays: ids of the modules connected to the relay
+}
+
+// UnmarshalJSON allows to automatically convert data to go types
+func (m *Module) UnmarshalJSON(data []byte) (err error) {
+	// Add tmp type
+	type OriginalUnmarshal Module
+	tmp := struct {
+		SetupDate     int64    `json:"setup_date"`     // date the module was setup
+		ModuleBridged []string `json:"module_bridged"` // documented key for modules_bridged
+		*OriginalUnmarshal
+	}{
+		OriginalUnmarshal: (*OriginalUnmarshal)(m),
+	}
+	// Unmarshall into the tmp fields
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		err = fmt.Errorf("failed to unmarshal data to the temporary Module struct: %w", err)
+		return
+	}
+	// Convert
+	m.SetupDate = timeFromUnix(tmp.SetupDate)
+	if len(m.ModulesBridged) == 0 {
+		m.ModulesBridged = tmp.ModuleBridged
+	}
+	return
+}
+
+// Schedule represents a weekly heating schedule
+type Schedule struct {
+	ID        string           `json:"id"`        // id of the schedule
+	Name      string           `json:"name"`      // name of the schedule
+	Type      string           `json:"type"`      // type of the schedule (therm for heating schedules)
+	Default   bool             `json:"default"`   // true if this is the default schedule
+	Selected  bool             `json:"selected"`  // true if this is the schedule currently in use
+	AwayTemp  float64          `json:"away_temp"` // temperature used in away mode (°C)
+	HGTemp    float64          `json:"hg_temp"`   // temperature used in frost guard mode (°C)
+	Timetable []TimetableEntry `json:"timetable"` // zones activations, sorted by offset
+	Zones     []Zone           `json:"zones"`     // zones used by the timetable
+}
+
+// Zone returns the zone matching zoneID
+func (s Schedule) Zone(zoneID int) (zone Zone, found bool) {
+	for _, zone = range s.Zones {
+		if zone.ID == zoneID {
+			found = true
+			return
+		}
+	}
+	return Zone{}, false
+}
+
+// TimetableEntry activates a zone at a given time of the week
+type TimetableEntry struct {
+	ZoneID int `json:"zone_id"`  // id of the zone to activate
+	Offset int `json:"m_offset"` // offset in minutes since Monday 00:00
+}
+
+// Zone represents a set of rooms setpoints which can be activated by a timetable entry
+type Zone struct {
+	ID        int            `json:"id"`         // id of the zone
+	Name      string         `json:"name"`       // name of the zone
+	Type      ZoneType       `json:"type"`       // type of the zone (see ZoneType const values)
+	Rooms     []ZoneRoom     `json:"rooms"`      // rooms setpoints
+	RoomsTemp []ZoneRoomTemp `json:"rooms_temp"` // rooms setpoints (alternative form also sent by the API)
+}
+
+// Setpoints returns the setpoint temperature of each room of the zone, whatever the form used by the API
+func (z Zone) Setpoints() (setpoints map[RoomID]float64) {
+	setpoints = make(map[RoomID]float64, len(z.Rooms)+len(z.RoomsTemp))
+	for _, room := range z.RoomsTemp {
+		setpoints[room.RoomID] = room.Temp
+	}
+	for _, room := range z.Rooms {
+		setpoints[room.ID] = room.ThermSetpointTemperature
+	}
+	return
+}
+
+// ZoneRoom is the setpoint of a room within a zone
+type ZoneRoom struct {
+	ID                       RoomID  `json:"id"`                         // id of the room
+	ThermSetpointTemperature float64 `json:"therm_setpoint_temperature"` // setpoint temperature (°C)
+}
+
+// ZoneRoomTemp is the setpoint of a room within a zone
+type ZoneRoomTemp struct {
+	RoomID RoomID  `json:"room_id"` // id of the room
+	Temp   float64 `json:"temp"`    // setpoint temperature (°C)
+}
@@ -0,0 +1,81 @@
+package energy
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hekmon/go-netatmo/internal/measures"
+)
+
+// MeasureSeries represents a time series of measures as returned by GetRoomMeasure() and GetMeasure()
+type MeasureSeries struct {
+	Types  []MeasureType // measure types, in the same order as the values of each point
+	Points MeasurePoints // points sorted by time
+}
+
+// In returns a copy of the series with all its points times expressed in loc
+func (ms MeasureSeries) In(loc *time.Location) MeasureSeries {
+	if ms.Points != nil {
+		points := make(MeasurePoints, len(ms.Points))
+		for index, point := range ms.Points {
+			point.Time = point.Time.In(loc)
+			points[index] = point
+		}
+		ms.Points = points
+	}
+	return ms
+}
+
+// Values returns the values of a measure type, points without data for this type are skipped
+func (ms MeasureSeries) Values(measureType MeasureType) (values []MeasureValue) {
+	index := ms.typeIndex(measureType)
+	if index == -1 {
+		return
+	}
+	values = make([]MeasureValue, 0, len(ms.Points))
+	for _, point := range ms.Points {
+		if point.Values[index] != nil {
+			values = append(values, MeasureValue{Time: point.Time, Value: *point.Values[index]})
+		}
+	}
+	return
+}
+
+// At returns the last value of a measure type measured at or before t. It allows to line up series with
+// different timestamps (for example a room temperature with an outdoor temperature).
+func (ms MeasureSeries) At(measureType MeasureType, t time.Time) (value MeasureValue, found bool) {
+	index := ms.typeIndex(measureType)
+	if index == -1 {
+		return
+	}
+	last := sort.Search(len(ms.Points), func(i int) bool {
+		return ms.Points[i].Time.After(t)
+	}) - 1
+	for ; last >= 0; last-- {
+		if ms.Points[last].Values[index] != nil {
+			return MeasureValue{Time: ms.Points[last].Time, Value: *ms.Points[last].Values[index]}, true
+		}
+	}
+	return
+}
+
+func (ms MeasureSeries) typeIndex(measureType MeasureType) int {
+	for index, mt := range ms.Types {
+		if mt == measureType {
+			return index
+		}
+	}
+	return -1
+}
+
+// MeasureValue is a single value of a series
+type MeasureValue struct {
+	Time  time.Time
+	Value float64
+}
+
+// MeasurePoint represents the values of a series at a given time. A nil value means no data.
+type MeasurePoint = measures.Point
+
+// MeasurePoints is a collection of MeasurePoint
+type MeasurePoints = measures.Points
@@ -0,0 +1,370 @@
+package energy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// HomeStatus contains the current state of the rooms and modules of a home
+type HomeStatus struct {
+	ID      string         `json:"id"`
+	Rooms   []RoomStatus   `json:"rooms"`
+	Modules []ModuleStatus `json:"modules"`
+}
+
+// Room returns the status of the room matching roomID
+func (hs HomeStatus) Room(roomID RoomID) (room RoomStatus, found bool) {
+	for _, room = range hs.Rooms {
+		if room.ID == roomID {
+			found = true
+			return
+		}
+	}
+	return RoomStatus{}, false
+}
+
+// Module returns the status of the module matching moduleID
+func (hs HomeStatus) Module(moduleID string) (module ModuleStatus, found bool) {
+	for _, module = range hs.Modules {
+		if module.ID == moduleID {
+			found = true
+			return
+		}
+	}
+	return ModuleStatus{}, false
+}
+
+// RoomStatus contains the current state of a room
+type RoomStatus struct {
+	ID                  RoomID       `json:"id"`                         // id of the room
+	Reachable           bool         `json:"reachable"`                  // false if none of the modules of the room are reachable
+	MeasuredTemperature float64      `json:"therm_measured_temperature"` // measured temperature (°C)
+	SetpointTemperature float64      `json:"therm_setpoint_temperature"` // setpoint temperature (°C)
+	SetpointMode        SetpointMode `json:"therm_setpoint_mode"`        // origin of the current setpoint (see SetpointMode const values)
+	SetpointStartTime   time.Time    `json:"-"`                          // start time of a manual setpoint (zero if not set)
+	SetpointEndTime     time.Time    `json:"-"`                          // end time of a manual setpoint (zero if not set)
+	Anticipating        bool         `json:"anticipating"`               // true if the room is heating in advance to reach the next scheduled setpoint in time
+	OpenWindow          bool         `json:"open_windows"`               // true if an open window has been detected
+}
+
+// UnmarshalJSON allows to automatically convert data to go types
+func (rs *RoomStatus) UnmarshalJSON(data []byte) (err error) {
+	// Add tmp type
+	type OriginalUnmarshal RoomStatus
+	tmp := struct {
+		SetpointStartTime int64 `json:"therm_setpoint_start_time"` // start time of a manual setpoint
+		SetpointEndTime   int64 `json:"therm_setpoint_end_time"`   // end time of a manual setpoint
+		*OriginalUnmarshal
+	}{
+		OriginalUnmarshal: (*OriginalUnmarshal)(rs),
+	}
+	// Unmarshall into the tmp fields
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		err = fmt.Errorf("failed to unmarshal data to the temporary RoomStatus struct: %w", err)
+		return
+	}
+	// Convert
+	rs.SetpointStartTime = timeFromUnix(tmp.SetpointStartTime)
+	rs.SetpointEndTime = timeFromUnix(tmp.SetpointEndTime)
+	return
+}
+
+// SetpointMode represents the origin of a room setpoint
+type SetpointMode string
+
+const (
+	// SetpointModeManual represents a manual setpoint
+	SetpointModeManual SetpointMode = "manual"
+	// SetpointModeMax represents the maximum temperature (boost)
+	SetpointModeMax SetpointMode = "max"
+	// SetpointModeOff represents a room with heating off
+	SetpointModeOff SetpointMode = "off"
+	// SetpointModeSchedule represents a setpoint coming from the home schedule
+	SetpointModeSchedule SetpointMode = "schedule"
+	// SetpointModeAway represents the away temperature of the home schedule
+	SetpointModeAway SetpointMode = "away"
+	// SetpointModeFrostGuard represents the frost guard temperature of the home schedule
+	SetpointModeFrostGuard SetpointMode = "hg"
+	// SetpointModeHome is only used to set a room back to the home mode (see SetRoomThermPoint())
+	SetpointModeHome SetpointMode = "home"
+)
+
+// String implements the https://golang.org/pkg/fmt/#Stringer interface
+func (sm SetpointMode) String() string {
+	switch sm {
+	case SetpointModeManual:
+		return "manual"
+	case SetpointModeMax:
+		return "max"
+	case SetpointModeOff:
+		return "off"
+	case SetpointModeSchedule:
+		return "schedule"
+	case SetpointModeAway:
+		return "away"
+	case SetpointModeFrostGuard:
+		return "frost guard"
+	case SetpointModeHome:
+		return "home"
+	default:
+		return "<unknown>"
+	}
+}
+
+// GoString implements the https://golang.org/pkg/fmt/#GoStringer interface
+func (sm SetpointMode) GoString() string {
+	return fmt.Sprintf("%s (%s)", sm.String(), string(sm))
+}
+
+// EncodeValues allows go-querystring to encode the mode with its API value
+// (implements https://pkg.go.dev/github.com/google/go-querystring/query#Encoder)
+func (sm SetpointMode) EncodeValues(key string, v *url.Values) error {
+	if sm != "" {
+		v.Set(key, string(sm))
+	}
+	return nil
+}
+
+// UnmarshalText allows to decode the different spellings used by the API for the same mode
+// (implements https://pkg.go.dev/encoding#TextUnmarshaler)
+func (sm *SetpointMode) UnmarshalText(text []byte) error {
+	switch mode := string(text); mode {
+	case "frost_guard":
+		*sm = SetpointModeFrostGuard
+	default:
+		*sm = SetpointMode(mode)
+	}
+	return nil
+}
+
+// ModuleStatus contains the current state of a module. Fields are populated depending on the module type.
+type ModuleStatus struct {
+	ID                      string       `json:"id"`                         // id of the module
+	Type                    ModuleType   `json:"type"`                       // type of the module (see ModuleType const values)
+	Reachable               bool         `json:"reachable"`                  // thermostats and valves: true if the module can communicate with the relay
+	FirmwareRevision        int          `json:"firmware_revision"`          // firmware version
+	RFStrength              RadioQuality `json:"rf_strenght"`                // radio link quality (yes the API documentation has a typo on JSON key)
+	WiFiStrength            WiFiQuality  `json:"wifi_strenght"`              // relays: WiFi link quality (yes the API documentation has a typo on JSON key)
+	BatteryLevel            int          `json:"battery_level"`              // thermostats and valves: battery level in mV (see Battery())
+	BatteryState            BatteryState `json:"battery_state"`              // thermostats and valves: battery state computed by Netatmo
+	BoilerStatus            bool         `json:"boiler_status"`              // thermostats: true if the boiler is heating
+	BoilerValveComfortBoost bool         `json:"boiler_valve_comfort_boost"` // thermostats: true if a valve is asking for a boiler boost
+	Anticipating            bool         `json:"anticipating"`               // thermostats: true if heating in advance for the next scheduled setpoint
+	Bridge                  string       `json:"bridge"`                     // thermostats and valves: id of the relay the module is connected to
+}
+
+// UnmarshalJSON allows to automatically convert data to go types
+func (ms *ModuleStatus) UnmarshalJSON(data []byte) (err error) {
+	// Add tmp type
+	type OriginalUnmarshal ModuleStatus
+	tmp := struct {
+		RFStrength   *RadioQuality `json:"rf_strength"`   // key actually sent by the API
+		WiFiStrength *WiFiQuality  `json:"wifi_strength"` // key actually sent by the API
+		*OriginalUnmarshal
+	}{
+		OriginalUnmarshal: (*OriginalUnmarshal)(ms),
+	}
+	// Unmarshall into the tmp fields
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		err = fmt.Errorf("failed to unmarshal data to the temporary ModuleStatus struct: %w", err)
+		return
+	}
+	// Merge both spellings
+	if tmp.RFStrength != nil {
+		ms.RFStrength = *tmp.RFStrength
+	}
+	if tmp.WiFiStrength != nil {
+		ms.WiFiStrength = *tmp.WiFiStrength
+	}
+	return
+}
+
+// Battery returns the battery level of a thermostat (ThermostatBatteryLevel) or a valve (ValveBatteryLevel).
+// It returns nil for relays as they are not running on batteries.
+func (ms ModuleStatus) Battery() fmt.Stringer {
+	switch ms.Type {
+	case ModuleTypeThermostat:
+		return ThermostatBatteryLevel(ms.BatteryLevel)
+	case ModuleTypeValve:
+		return ValveBatteryLevel(ms.BatteryLevel)
+	default:
+		return nil
+	}
+}
+
+// RadioQuality represents the radio signal quality between a relay and its modules
+type RadioQuality int
+
+const (
+	// RadioQualityLow represents a low quality radio link
+	RadioQualityLow RadioQuality = 90
+	// RadioQualityMedium represents a medium quality radio link
+	RadioQualityMedium RadioQuality = 80
+	// RadioQualityHigh represents a high quality radio link
+	RadioQualityHigh RadioQuality = 70
+	// RadioQualityFull represents a full signal radio link
+	RadioQualityFull RadioQuality = 60
+)
+
+// String implements the https://golang.org/pkg/fmt/#Stringer interface
+func (rq RadioQuality) String() string {
+	switch {
+	case rq <= RadioQualityFull:
+		return "full"
+	case rq <= RadioQualityHigh:
+		return "high"
+	case rq <= RadioQualityMedium:
+		return "medium"
+	case rq <= RadioQualityLow:
+		return "low"
+	default:
+		return "very low"
+	}
+}
+
+// GoString implements the https://golang.org/pkg/fmt/#GoStringer interface
+func (rq RadioQuality) GoString() string {
+	return fmt.Sprintf("%s (%d)", rq, rq)
+}
+
+// WiFiQuality represents the WiFi strength signal of a relay
+type WiFiQuality int
+
+const (
+	// WiFiQualityBad represents a bad level for WiFi reception
+	WiFiQualityBad WiFiQuality = 86
+	// WiFiQualityAverage represents an average level for WiFi reception
+	WiFiQualityAverage WiFiQuality = 71
+	// WiFiQualityGood represents a good level for WiFi reception
+	WiFiQualityGood WiFiQuality = 56
+)
+
+// String implements the https://golang.org/pkg/fmt/#Stringer interface
+func (wq WiFiQuality) String() string {
+	switch {
+	case wq <= WiFiQualityGood:
+		return "good"
+	case wq <= WiFiQualityAverage:
+		return "average"
+	case wq <= WiFiQualityBad:
+		return "bad"
+	default:
+		return "very bad"
+	}
+}
+
+// GoString implements the https://golang.org/pkg/fmt/#GoStringer interface
+func (wq WiFiQuality) GoString() string {
+	return fmt.Sprintf("%s (%d)", wq, wq)
+}
+
+// BatteryState represents the battery state of a module as computed by Netatmo
+type BatteryState string
+
+const (
+	// BatteryStateVeryLow represents a battery which must be changed
+	BatteryStateVeryLow BatteryState = "very_low"
+	// BatteryStateLow represents a low battery
+	BatteryStateLow BatteryState = "low"
+	// BatteryStateMedium represents a medium battery
+	BatteryStateMedium BatteryState = "medium"
+	// BatteryStateHigh represents a high battery
+	BatteryStateHigh BatteryState = "high"
+	// BatteryStateFull represents a full battery (thermostats only)
+	BatteryStateFull BatteryState = "full"
+)
+
+// String implements the https://golang.org/pkg/fmt/#Stringer interface
+func (bs BatteryState) String() string {
+	switch bs {
+	case BatteryStateVeryLow:
+		return "very low"
+	case BatteryStateLow:
+		return "low"
+	case BatteryStateMedium:
+		return "medium"
+	case BatteryStateHigh:
+		return "high"
+	case BatteryStateFull:
+		return "full"
+	default:
+		return "<unknown>"
+	}
+}
+
+// GoString implements the https://golang.org/pkg/fmt/#GoStringer interface
+func (bs BatteryState) GoString() string {
+	return fmt.Sprintf("%s (%s)", bs.String(), string(bs))
+}
+
+// ThermostatBatteryLevel represents the battery level of a thermostat (in mV)
+type ThermostatBatteryLevel int
+
+const (
+	// ThermostatBatteryFull represents the full level of a thermostat battery
+	ThermostatBatteryFull ThermostatBatteryLevel = 4100
+	// ThermostatBatteryHigh represents the high level of a thermostat battery
+	ThermostatBatteryHigh ThermostatBatteryLevel = 3600
+	// ThermostatBatteryMedium represents the medium level of a thermostat battery
+	ThermostatBatteryMedium ThermostatBatteryLevel = 3300
+	// ThermostatBatteryLow represents the low level of a thermostat battery
+	ThermostatBatteryLow ThermostatBatteryLevel = 3000
+)
+
+// String implements the https://golang.org/pkg/fmt/#Stringer interface
+func (tbl ThermostatBatteryLevel) String() string {
+	switch {
+	case tbl >= ThermostatBatteryFull:
+		return "full"
+	case tbl >= ThermostatBatteryHigh:
+		return "high"
+	case tbl >= ThermostatBatteryMedium:
+		return "medium"
+	case tbl >= ThermostatBatteryLow:
+		return "low"
+	default:
+		return "very low"
+	}
+}
+
+// GoString implements the https://golang.org/pkg/fmt/#GoStringer interface
+func (tbl ThermostatBatteryLevel) GoString() string {
+	return fmt.Sprintf("%s (%d)", tbl, tbl)
+}
+
+// ValveBatteryLevel represents the battery level of a valve (in mV)
+type ValveBatteryLevel int
+
+const (
+	// ValveBatteryFull represents the full level of a valve battery
+	ValveBatteryFull ValveBatteryLevel = 3200
+	// ValveBatteryHigh represents the high level of a valve battery
+	ValveBatteryHigh ValveBatteryLevel = 2700
+	// ValveBatteryMedium represents the medium level of a valve battery
+	ValveBatteryMedium ValveBatteryLevel = 2400
+	// ValveBatteryLow represents the low level of a valve battery
+	ValveBatteryLow ValveBatteryLevel = 2200
+)
+
+// String implements the https://golang.org/pkg/fmt/#Stringer interface
+func (vbl ValveBatteryLevel) String() string {
+	switch {
+	case vbl >= ValveBatteryFull:
+		return "full"
+	case vbl >= ValveBatteryHigh:
+		return "high"
+	case vbl >= ValveBatteryMedium:
+		return "medium"
+	case vbl >= ValveBatteryLow:
+		return "low"
+	default:
+		return "very low"
+	}
+}
+
+// GoString implements the https://golang.org/pkg/fmt/#GoStringer interface
+func (vbl ValveBatteryLevel) GoString() string {
+	return fmt.Sprintf("%s (%d)", vbl, vbl)
+}
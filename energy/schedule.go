@@ -0,0 +1,211 @@
+package energy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// MinutesPerDay is the number of minutes within a day, used to compute timetable offsets
+	MinutesPerDay = 24 * 60
+	// MinutesPerWeek is the number of minutes within a week: timetable offsets must be lower than this value
+	MinutesPerWeek = 7 * MinutesPerDay
+	// MinFrostGuardTemp is the minimum frost guard temperature of a schedule (°C)
+	MinFrostGuardTemp = 5
+	// MaxFrostGuardTemp is the maximum frost guard temperature of a schedule (°C)
+	MaxFrostGuardTemp = 15
+	// MinAwayTemp is the minimum away temperature of a schedule (°C)
+	MinAwayTemp = MinRoomSetpoint
+	// MaxAwayTemp is the maximum away temperature of a schedule (°C)
+	MaxAwayTemp = MaxRoomSetpoint
+)
+
+// Days represents a set of days of the week (timetables start on Monday)
+type Days uint8
+
+const (
+	// Monday is the first day of a timetable week
+	Monday Days = 1 << iota
+	// Tuesday is the second day of a timetable week
+	Tuesday
+	// Wednesday is the third day of a timetable week
+	Wednesday
+	// Thursday is the fourth day of a timetable week
+	Thursday
+	// Friday is the fifth day of a timetable week
+	Friday
+	// Saturday is the sixth day of a timetable week
+	Saturday
+	// Sunday is the last day of a timetable week
+	Sunday
+	// Weekdays contains Monday to Friday
+	Weekdays = Monday | Tuesday | Wednesday | Thursday | Friday
+	// Weekend contains Saturday and Sunday
+	Weekend = Saturday | Sunday
+	// EveryDay contains all the days of the week
+	EveryDay = Weekdays | Weekend
+)
+
+var daysNames = [7]string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// Contains returns true if the day at index (0 for Monday, 6 for Sunday) is part of the set
+func (d Days) Contains(index int) bool {
+	return index >= 0 && index < 7 && d&(1<<uint(index)) != 0
+}
+
+// String implements the https://golang.org/pkg/fmt/#Stringer interface
+func (d Days) String() string {
+	switch d {
+	case 0:
+		return "none"
+	case EveryDay:
+		return "everyday"
+	case Weekdays:
+		return "weekdays"
+	case Weekend:
+		return "weekend"
+	}
+	names := make([]string, 0, 7)
+	for index, name := range daysNames {
+		if d.Contains(index) {
+			names = append(names, name[:3])
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// GoString implements the https://golang.org/pkg/fmt/#GoStringer interface
+func (d Days) GoString() string {
+	return fmt.Sprintf("%s (%d)", d.String(), d)
+}
+
+// ParseDays parses a comma separated list of days specifications. A specification can be a keyword
+// (weekdays, weekend, everyday or daily), a day name (full or 3 letters) or a range of days (mon-fri, fri-mon).
+func ParseDays(spec string) (days Days, err error) {
+	for _, item := range strings.Split(strings.ToLower(spec), ",") {
+		item = strings.TrimSpace(item)
+		switch item {
+		case "weekdays":
+			days |= Weekdays
+			continue
+		case "weekend", "weekends":
+			days |= Weekend
+			continue
+		case "everyday", "daily":
+			days |= EveryDay
+			continue
+		}
+		bounds := strings.SplitN(item, "-", 2)
+		first, found := dayIndex(bounds[0])
+		if !found {
+			return 0, fmt.Errorf("unknown day: '%s'", bounds[0])
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, found = dayIndex(bounds[1]); !found {
+				return 0, fmt.Errorf("unknown day: '%s'", bounds[1])
+			}
+		}
+		for index := first; ; index = (index + 1) % 7 {
+			days |= 1 << uint(index)
+			if index == last {
+				break
+			}
+		}
+	}
+	return
+}
+
+func dayIndex(name string) (index int, found bool) {
+	name = strings.TrimSpace(name)
+	if len(name) < 3 {
+		return
+	}
+	for index = range daysNames {
+		if strings.HasPrefix(daysNames[index], name) {
+			return index, true
+		}
+	}
+	return
+}
+
+// Validate verifies the schedule can be sent to the API for home h: the timetable must cover the whole week
+// (starting on Monday 00:00) with sorted offsets, reference existing zones, and each zone must have a valid
+// temperature for every room of the home. Frost guard and away temperatures must be in range.
+func (s Schedule) Validate(h Home) error {
+	// temperatures
+	if s.HGTemp < MinFrostGuardTemp || s.HGTemp > MaxFrostGuardTemp {
+		return fmt.Errorf("frost guard temperature must be between %d°C and %d°C: %v°C", MinFrostGuardTemp, MaxFrostGuardTemp, s.HGTemp)
+	}
+	if s.AwayTemp < MinAwayTemp || s.AwayTemp > MaxAwayTemp {
+		return fmt.Errorf("away temperature must be between %d°C and %d°C: %v°C", MinAwayTemp, MaxAwayTemp, s.AwayTemp)
+	}
+	// zones
+	if len(s.Zones) == 0 {
+		return errors.New("schedule has no zone")
+	}
+	rooms := make(map[RoomID]bool, len(h.Rooms))
+	for _, room := range h.Rooms {
+		rooms[room.ID] = true
+	}
+	zones := make(map[int]bool, len(s.Zones))
+	for _, zone := range s.Zones {
+		if zones[zone.ID] {
+			return fmt.Errorf("zone ID %d is used by several zones", zone.ID)
+		}
+		zones[zone.ID] = true
+		setpoints := zone.Setpoints()
+		for _, room := range h.Rooms {
+			temp, found := setpoints[room.ID]
+			if !found {
+				return fmt.Errorf("zone '%s' has no temperature for room '%s' (%s)", zone.Name, room.Name, room.ID)
+			}
+			if temp < MinRoomSetpoint || temp > MaxRoomSetpoint {
+				return fmt.Errorf("zone '%s' temperature for room '%s' (%s) must be between %d°C and %d°C: %v°C",
+					zone.Name, room.Name, room.ID, MinRoomSetpoint, MaxRoomSetpoint, temp)
+			}
+		}
+		for _, room := range zone.Rooms {
+			if !rooms[room.ID] {
+				return fmt.Errorf("zone '%s' references an unknown room: %s", zone.Name, room.ID)
+			}
+		}
+		for _, room := range zone.RoomsTemp {
+			if !rooms[room.RoomID] {
+				return fmt.Errorf("zone '%s' references an unknown room: %s", zone.Name, room.RoomID)
+			}
+		}
+	}
+	// timetable
+	if len(s.Timetable) == 0 {
+		return errors.New("timetable is empty")
+	}
+	if s.Timetable[0].Offset != 0 {
+		return fmt.Errorf("timetable does not cover the whole week: first entry must start on Monday 00:00 (offset %d)",
+			s.Timetable[0].Offset)
+	}
+	for index, entry := range s.Timetable {
+		if entry.Offset < 0 || entry.Offset >= MinutesPerWeek {
+			return fmt.Errorf("timetable entry #%d: offset %d is out of the week", index, entry.Offset)
+		}
+		if index > 0 && entry.Offset <= s.Timetable[index-1].Offset {
+			return fmt.Errorf("timetable entry #%d: offset %d is not after the previous one (%d)",
+				index, entry.Offset, s.Timetable[index-1].Offset)
+		}
+		if !zones[entry.ZoneID] {
+			return fmt.Errorf("timetable entry #%d: unknown zone ID %d", index, entry.ZoneID)
+		}
+	}
+	return nil
+}
+
+// FormatOffset returns a human readable version of a timetable offset (for example "Tue 06:30")
+func FormatOffset(offset int) string {
+	day := offset / MinutesPerDay
+	if day < 0 || day > 6 {
+		return fmt.Sprintf("<invalid offset %d>", offset)
+	}
+	minutes := offset % MinutesPerDay
+	return fmt.Sprintf("%s %02d:%02d", strings.ToUpper(daysNames[day][:1])+daysNames[day][1:3], minutes/60, minutes%60)
+}
@@ -0,0 +1,279 @@
+package energy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultAwayTemp is the away temperature used by ScheduleBuilder when none is set (°C)
+	DefaultAwayTemp = 12
+	// DefaultFrostGuardTemp is the frost guard temperature used by ScheduleBuilder when none is set (°C)
+	DefaultFrostGuardTemp = 7
+)
+
+// ScheduleBuilder helps to write a weekly heating schedule without computing timetable offsets by hand:
+//
+//	builder := energy.NewScheduleBuilder(home, scheduleID, "Winter")
+//	builder.Zone("Comfort", energy.ZoneTypeComfort, 20).Room(bathroomID, 22)
+//	builder.Zone("Night", energy.ZoneTypeNight, 17)
+//	builder.Program("weekdays 06:30 Comfort, 22:00 Night")
+//	builder.Program("weekend 08:00 Comfort, 23:00 Night")
+//	schedule, err := builder.Build()
+//
+// A zone stays active until the next transition, even across days: the zone active on Monday 00:00 is the
+// last one of the week. Errors encountered while building are reported by Build().
+type ScheduleBuilder struct {
+	home     Home
+	id       string
+	name     string
+	awayTemp float64
+	hgTemp   float64
+	zones    []*ScheduleZoneBuilder
+	days     [7]map[int]*ScheduleZoneBuilder // day index -> minute of the day -> zone
+	err      error
+}
+
+// NewScheduleBuilder returns a builder for the schedule scheduleID of home h. If the schedule already exists
+// within the home, its away and frost guard temperatures are used as default values.
+func NewScheduleBuilder(h Home, scheduleID, name string) (sb *ScheduleBuilder) {
+	sb = &ScheduleBuilder{
+		home:     h,
+		id:       scheduleID,
+		name:     name,
+		awayTemp: DefaultAwayTemp,
+		hgTemp:   DefaultFrostGuardTemp,
+	}
+	if schedule, found := h.Schedule(scheduleID); found {
+		sb.awayTemp = schedule.AwayTemp
+		sb.hgTemp = schedule.HGTemp
+	}
+	for index := range sb.days {
+		sb.days[index] = make(map[int]*ScheduleZoneBuilder)
+	}
+	return
+}
+
+// AwayTemp sets the temperature used when the home is in away mode
+func (sb *ScheduleBuilder) AwayTemp(temp float64) *ScheduleBuilder {
+	sb.awayTemp = temp
+	return sb
+}
+
+// FrostGuardTemp sets the temperature used when the home is in frost guard mode
+func (sb *ScheduleBuilder) FrostGuardTemp(temp float64) *ScheduleBuilder {
+	sb.hgTemp = temp
+	return sb
+}
+
+// Zone declares a new zone. If defaultTemp is not 0, it is used for every room of the home: use Room() on the
+// returned zone builder to override it for specific rooms.
+func (sb *ScheduleBuilder) Zone(name string, zoneType ZoneType, defaultTemp float64) (zb *ScheduleZoneBuilder) {
+	zb = &ScheduleZoneBuilder{
+		id:       len(sb.zones),
+		name:     name,
+		zoneType: zoneType,
+		temps:    make(map[RoomID]float64, len(sb.home.Rooms)),
+	}
+	if _, found := sb.zone(name); found {
+		sb.fail(fmt.Errorf("zone '%s' is declared several times", name))
+	}
+	if defaultTemp != 0 {
+		for _, room := range sb.home.Rooms {
+			zb.temps[room.ID] = defaultTemp
+		}
+	}
+	sb.zones = append(sb.zones, zb)
+	return
+}
+
+// Days activates zones on the given days. transitions is a comma separated list of "HH:MM zone name" items,
+// for example "06:30 Comfort, 22:00 Night". Transitions set for the same day and time by a previous call are replaced.
+func (sb *ScheduleBuilder) Days(days Days, transitions string) *ScheduleBuilder {
+	if days == 0 {
+		sb.fail(errors.New("no day selected"))
+		return sb
+	}
+	for _, item := range strings.Split(transitions, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		fields := strings.SplitN(item, " ", 2)
+		if len(fields) != 2 {
+			sb.fail(fmt.Errorf("invalid transition '%s': expecting 'HH:MM zone name'", item))
+			continue
+		}
+		minute, err := parseMinuteOfDay(fields[0])
+		if err != nil {
+			sb.fail(fmt.Errorf("invalid transition '%s': %w", item, err))
+			continue
+		}
+		zb, found := sb.zone(fields[1])
+		if !found {
+			sb.fail(fmt.Errorf("invalid transition '%s': unknown zone '%s'", item, strings.TrimSpace(fields[1])))
+			continue
+		}
+		for index := range sb.days {
+			if days.Contains(index) {
+				sb.days[index][minute] = zb
+			}
+		}
+	}
+	return sb
+}
+
+// Program parses a line composed of a days specification (see ParseDays()) followed by the transitions
+// (see Days()), for example "weekdays 06:30 Comfort, 22:00 Night" or "sat-sun 08:00 Comfort, 23:00 Night".
+func (sb *ScheduleBuilder) Program(line string) *ScheduleBuilder {
+	fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+	if len(fields) != 2 {
+		sb.fail(fmt.Errorf("invalid program '%s': expecting 'days HH:MM zone, HH:MM zone, ...'", line))
+		return sb
+	}
+	days, err := ParseDays(fields[0])
+	if err != nil {
+		sb.fail(fmt.Errorf("invalid program '%s': %w", line, err))
+		return sb
+	}
+	return sb.Days(days, fields[1])
+}
+
+// Build compiles the zones and the transitions into a schedule and validates it against the home
+func (sb *ScheduleBuilder) Build() (schedule Schedule, err error) {
+	if sb.err != nil {
+		err = sb.err
+		return
+	}
+	schedule = Schedule{
+		ID:       sb.id,
+		Name:     sb.name,
+		Type:     "therm",
+		AwayTemp: sb.awayTemp,
+		HGTemp:   sb.hgTemp,
+		Zones:    make([]Zone, len(sb.zones)),
+	}
+	// zones
+	for index, zb := range sb.zones {
+		schedule.Zones[index] = zb.zone(sb.home)
+	}
+	// timetable
+	for index, transitions := range sb.days {
+		minutes := make([]int, 0, len(transitions))
+		for minute := range transitions {
+			minutes = append(minutes, minute)
+		}
+		sort.Ints(minutes)
+		for _, minute := range minutes {
+			schedule.Timetable = appendTimetableEntry(schedule.Timetable, TimetableEntry{
+				ZoneID: transitions[minute].id,
+				Offset: index*MinutesPerDay + minute,
+			})
+		}
+	}
+	if len(schedule.Timetable) == 0 {
+		err = errors.New("no transition defined")
+		return
+	}
+	if schedule.Timetable[0].Offset != 0 {
+		// the week starts with the last zone of the previous week
+		timetable := []TimetableEntry{{
+			ZoneID: schedule.Timetable[len(schedule.Timetable)-1].ZoneID,
+			Offset: 0,
+		}}
+		for _, entry := range schedule.Timetable {
+			timetable = appendTimetableEntry(timetable, entry)
+		}
+		schedule.Timetable = timetable
+	}
+	// verify
+	if err = schedule.Validate(sb.home); err != nil {
+		err = fmt.Errorf("invalid schedule: %w", err)
+	}
+	return
+}
+
+func (sb *ScheduleBuilder) zone(name string) (zb *ScheduleZoneBuilder, found bool) {
+	name = strings.TrimSpace(name)
+	for _, zb = range sb.zones {
+		if strings.EqualFold(zb.name, name) {
+			return zb, true
+		}
+	}
+	return nil, false
+}
+
+func (sb *ScheduleBuilder) fail(err error) {
+	if sb.err == nil {
+		sb.err = err
+	}
+}
+
+// appendTimetableEntry appends entry to timetable unless it activates the zone already active
+func appendTimetableEntry(timetable []TimetableEntry, entry TimetableEntry) []TimetableEntry {
+	if len(timetable) > 0 && timetable[len(timetable)-1].ZoneID == entry.ZoneID {
+		return timetable
+	}
+	return append(timetable, entry)
+}
+
+func parseMinuteOfDay(value string) (minute int, err error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 || len(parts[1]) != 2 {
+		return 0, fmt.Errorf("invalid time '%s': expecting HH:MM", value)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil || hours < 0 || hours > 23 {
+		return 0, fmt.Errorf("invalid hours in '%s'", value)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes < 0 || minutes > 59 {
+		return 0, fmt.Errorf("invalid minutes in '%s'", value)
+	}
+	return hours*60 + minutes, nil
+}
+
+// ScheduleZoneBuilder allows to set the rooms temperatures of a zone declared with ScheduleBuilder.Zone()
+type ScheduleZoneBuilder struct {
+	id       int
+	name     string
+	zoneType ZoneType
+	temps    map[RoomID]float64
+}
+
+// Room sets the temperature of a room for this zone
+func (zb *ScheduleZoneBuilder) Room(roomID RoomID, temp float64) *ScheduleZoneBuilder {
+	zb.temps[roomID] = temp
+	return zb
+}
+
+func (zb *ScheduleZoneBuilder) zone(h Home) (zone Zone) {
+	zone = Zone{
+		ID:    zb.id,
+		Name:  zb.name,
+		Type:  zb.zoneType,
+		Rooms: make([]ZoneRoom, 0, len(zb.temps)),
+	}
+	// home rooms first (in the home order) then unknown rooms to let the validation report them
+	done := make(map[RoomID]bool, len(zb.temps))
+	for _, room := range h.Rooms {
+		if temp, found := zb.temps[room.ID]; found {
+			zone.Rooms = append(zone.Rooms, ZoneRoom{ID: room.ID, ThermSetpointTemperature: temp})
+			done[room.ID] = true
+		}
+	}
+	others := make([]string, 0, len(zb.temps)-len(done))
+	for roomID := range zb.temps {
+		if !done[roomID] {
+			others = append(others, string(roomID))
+		}
+	}
+	sort.Strings(others)
+	for _, roomID := range others {
+		zone.Rooms = append(zone.Rooms, ZoneRoom{ID: RoomID(roomID), ThermSetpointTemperature: zb.temps[RoomID(roomID)]})
+	}
+	return
+}
@@ -0,0 +1,335 @@
+package energy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ZoneAt returns the zone of the timetable active at t and the time of the next timetable transition.
+// Offsets are wall clock minutes since Monday 00:00 in the location of t: use the home time zone.
+func (s Schedule) ZoneAt(t time.Time) (zone Zone, next time.Time, err error) {
+	if len(s.Timetable) == 0 {
+		err = errors.New("timetable is empty")
+		return
+	}
+	day := (int(t.Weekday()) + 6) % 7 // Monday is 0
+	weekStart := time.Date(t.Year(), t.Month(), t.Day()-day, 0, 0, 0, 0, t.Location())
+	offset := day*MinutesPerDay + t.Hour()*60 + t.Minute()
+	// active entry is the last one started, or the last one of the previous week
+	index := sort.Search(len(s.Timetable), func(i int) bool {
+		return s.Timetable[i].Offset > offset
+	}) - 1
+	if index < 0 {
+		index = len(s.Timetable) - 1
+	}
+	if index+1 < len(s.Timetable) {
+		next = offsetTime(weekStart, s.Timetable[index+1].Offset)
+	} else {
+		next = offsetTime(weekStart, MinutesPerWeek+s.Timetable[0].Offset)
+	}
+	var found bool
+	if zone, found = s.Zone(s.Timetable[index].ZoneID); !found {
+		err = fmt.Errorf("timetable references an unknown zone ID: %d", s.Timetable[index].ZoneID)
+	}
+	return
+}
+
+func offsetTime(weekStart time.Time, offset int) time.Time {
+	return time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day()+offset/MinutesPerDay,
+		0, offset%MinutesPerDay, 0, 0, weekStart.Location())
+}
+
+// Setpoint is the effective setpoint of a room for a period of time
+type Setpoint struct {
+	RoomID      RoomID
+	From        time.Time    // evaluation instant or transition time (home time zone)
+	Until       time.Time    // next change of the setpoint (zero if no change is foreseeable)
+	Mode        SetpointMode // schedule, away or hg when set by the home, manual, max or off when overridden for the room
+	ScheduleID  string       // schedule in use by the home
+	Zone        *Zone        // active zone of the schedule, nil if the setpoint does not come from the timetable
+	Temperature float64      // target temperature (°C), 0 if the heating is off
+}
+
+func (sp Setpoint) same(other Setpoint) bool {
+	if sp.Mode != other.Mode || sp.ScheduleID != other.ScheduleID || sp.Temperature != other.Temperature {
+		return false
+	}
+	if sp.Zone == nil || other.Zone == nil {
+		return sp.Zone == other.Zone
+	}
+	return sp.Zone.ID == other.Zone.ID
+}
+
+// Evaluator computes the effective setpoint of the rooms of a home at any instant, from its schedules, its
+// heating mode and the manual overrides of its rooms. Changes can be simulated on top of the current state
+// with SwitchSchedule(), SetThermMode() and SetRoomThermPoint(). An Evaluator is not safe for concurrent use
+// while simulating changes.
+type Evaluator struct {
+	home      Home
+	location  *time.Location
+	schedules []scheduleSwitch
+	modes     []modeChange
+	overrides map[RoomID][]roomOverride
+}
+
+type scheduleSwitch struct {
+	at         time.Time
+	scheduleID string
+}
+
+type modeChange struct {
+	at   time.Time
+	mode ThermMode
+	end  time.Time
+}
+
+type roomOverride struct {
+	at   time.Time
+	mode SetpointMode
+	temp float64
+	end  time.Time
+}
+
+// NewEvaluator returns an evaluator for home h (from GetHomesData()) using the rooms overrides of status
+// (from GetHomeStatus(), can be empty)
+func NewEvaluator(h Home, status HomeStatus) (e *Evaluator, err error) {
+	schedule, found := h.SelectedSchedule()
+	if !found {
+		err = errors.New("home has no selected schedule")
+		return
+	}
+	mode := h.ThermMode
+	if mode == "" {
+		mode = ThermModeSchedule
+	}
+	e = &Evaluator{
+		home:      h,
+		location:  h.Timezone,
+		schedules: []scheduleSwitch{{scheduleID: schedule.ID}},
+		modes:     []modeChange{{mode: mode, end: h.ThermModeEndTime}},
+		overrides: make(map[RoomID][]roomOverride, len(status.Rooms)),
+	}
+	if e.location == nil {
+		e.location = time.UTC
+	}
+	for _, room := range status.Rooms {
+		switch room.SetpointMode {
+		case SetpointModeManual, SetpointModeMax, SetpointModeOff:
+			e.overrides[room.ID] = []roomOverride{{
+				at:   room.SetpointStartTime,
+				mode: room.SetpointMode,
+				temp: room.SetpointTemperature,
+				end:  room.SetpointEndTime,
+			}}
+		}
+	}
+	return
+}
+
+// SwitchSchedule simulates the selection of another schedule from at
+func (e *Evaluator) SwitchSchedule(at time.Time, scheduleID string) error {
+	if _, found := e.home.Schedule(scheduleID); !found {
+		return fmt.Errorf("unknown schedule: %s", scheduleID)
+	}
+	e.schedules = append(e.schedules, scheduleSwitch{at: at, scheduleID: scheduleID})
+	sort.SliceStable(e.schedules, func(i, j int) bool {
+		return e.schedules[i].at.Before(e.schedules[j].at)
+	})
+	return nil
+}
+
+// SetThermMode simulates a change of the home heating mode from at, until end (zero for no end). As the API
+// does, changing the home mode cancels the rooms overrides.
+func (e *Evaluator) SetThermMode(at time.Time, mode ThermMode, end time.Time) error {
+	switch mode {
+	case ThermModeSchedule:
+		if !end.IsZero() {
+			return fmt.Errorf("end time can not be set with the %s mode", mode)
+		}
+	case ThermModeAway, ThermModeFrostGuard:
+	default:
+		return fmt.Errorf("unsupported therm mode: %s", string(mode))
+	}
+	if !end.IsZero() && !end.After(at) {
+		return fmt.Errorf("end time must be after the start time: %v", end)
+	}
+	e.modes = append(e.modes, modeChange{at: at, mode: mode, end: end})
+	sort.SliceStable(e.modes, func(i, j int) bool {
+		return e.modes[i].at.Before(e.modes[j].at)
+	})
+	return nil
+}
+
+// SetRoomThermPoint simulates a room setpoint change from at. As the API does, a zero end time for the
+// manual and max modes uses the home default duration and SetpointModeHome cancels the current override.
+func (e *Evaluator) SetRoomThermPoint(at time.Time, roomID RoomID, mode SetpointMode, temp float64, end time.Time) error {
+	if _, found := e.home.Room(roomID); !found {
+		return fmt.Errorf("unknown room: %s", roomID)
+	}
+	switch mode {
+	case SetpointModeManual:
+		if temp < MinRoomSetpoint || temp > MaxRoomSetpoint {
+			return fmt.Errorf("manual temperature must be between %d°C and %d°C: %v°C", MinRoomSetpoint, MaxRoomSetpoint, temp)
+		}
+		fallthrough
+	case SetpointModeMax:
+		if end.IsZero() && e.home.ThermSetPointDefaultDuration > 0 {
+			end = at.Add(e.home.ThermSetPointDefaultDuration)
+		}
+	case SetpointModeHome:
+		end = time.Time{}
+	default:
+		return fmt.Errorf("unsupported setpoint mode: %s", string(mode))
+	}
+	if !end.IsZero() && !end.After(at) {
+		return fmt.Errorf("end time must be after the start time: %v", end)
+	}
+	overrides := append(e.overrides[roomID], roomOverride{at: at, mode: mode, temp: temp, end: end})
+	sort.SliceStable(overrides, func(i, j int) bool {
+		return overrides[i].at.Before(overrides[j].at)
+	})
+	e.overrides[roomID] = overrides
+	return nil
+}
+
+// Evaluate returns the effective setpoint of a room at the given instant
+func (e *Evaluator) Evaluate(roomID RoomID, at time.Time) (setpoint Setpoint, err error) {
+	if _, found := e.home.Room(roomID); !found {
+		err = fmt.Errorf("unknown room: %s", roomID)
+		return
+	}
+	setpoint, next, err := e.evaluate(roomID, at)
+	if err != nil {
+		return
+	}
+	// skip the events which do not change the setpoint (a complete week of each timetable at most)
+	maxSteps := 2 + 2*(len(e.schedules)+len(e.modes))
+	for _, schedule := range e.home.Schedules {
+		maxSteps += len(schedule.Timetable)
+	}
+	for _, overrides := range e.overrides {
+		maxSteps += 2 * len(overrides)
+	}
+	for step := 0; !next.IsZero() && step < maxSteps; step++ {
+		var candidate Setpoint
+		current := next
+		if candidate, next, err = e.evaluate(roomID, current); err != nil {
+			return
+		}
+		if !candidate.same(setpoint) {
+			setpoint.Until = current
+			return
+		}
+	}
+	return
+}
+
+// EvaluateAll returns the effective setpoint of every room of the home at the given instant
+func (e *Evaluator) EvaluateAll(at time.Time) (setpoints []Setpoint, err error) {
+	setpoints = make([]Setpoint, len(e.home.Rooms))
+	for index, room := range e.home.Rooms {
+		if setpoints[index], err = e.Evaluate(room.ID, at); err != nil {
+			err = fmt.Errorf("can not evaluate room '%s' (%s): %w", room.Name, room.ID, err)
+			return
+		}
+	}
+	return
+}
+
+// Transitions returns the successive setpoints of a room between from and until: the first one is the
+// setpoint active at from, the next ones start at each change.
+func (e *Evaluator) Transitions(roomID RoomID, from, until time.Time) (setpoints []Setpoint, err error) {
+	setpoint, err := e.Evaluate(roomID, from)
+	for err == nil {
+		setpoints = append(setpoints, setpoint)
+		if setpoint.Until.IsZero() || !setpoint.Until.Before(until) {
+			return
+		}
+		setpoint, err = e.Evaluate(roomID, setpoint.Until)
+	}
+	return
+}
+
+// evaluate computes the setpoint at t and the next instant something may change
+func (e *Evaluator) evaluate(roomID RoomID, t time.Time) (setpoint Setpoint, next time.Time, err error) {
+	t = t.In(e.location)
+	setpoint = Setpoint{
+		RoomID: roomID,
+		From:   t,
+	}
+	consider := func(candidate time.Time) {
+		if candidate.After(t) && (next.IsZero() || candidate.Before(next)) {
+			next = candidate.In(e.location)
+		}
+	}
+	// schedule in use
+	scheduleID := e.schedules[0].scheduleID
+	for _, sw := range e.schedules {
+		consider(sw.at)
+		if !sw.at.After(t) {
+			scheduleID = sw.scheduleID
+		}
+	}
+	schedule, _ := e.home.Schedule(scheduleID)
+	setpoint.ScheduleID = schedule.ID
+	// home mode
+	mode := e.modes[0]
+	for _, change := range e.modes {
+		consider(change.at)
+		consider(change.end)
+		if !change.at.After(t) {
+			mode = change
+		}
+	}
+	if !mode.end.IsZero() && !mode.end.After(t) {
+		mode = modeChange{at: mode.at, mode: ThermModeSchedule}
+	}
+	// room override (cancelled by a later home mode change)
+	var override *roomOverride
+	for index, candidate := range e.overrides[roomID] {
+		consider(candidate.at)
+		consider(candidate.end)
+		if !candidate.at.After(t) {
+			override = &e.overrides[roomID][index]
+		}
+	}
+	if override != nil && override.mode != SetpointModeHome && (override.end.IsZero() || override.end.After(t)) &&
+		(mode.at.IsZero() || override.at.After(mode.at)) {
+		setpoint.Mode = override.mode
+		switch override.mode {
+		case SetpointModeManual:
+			setpoint.Temperature = override.temp
+		case SetpointModeMax:
+			setpoint.Temperature = MaxRoomSetpoint
+		}
+		return
+	}
+	switch mode.mode {
+	case ThermModeAway:
+		setpoint.Mode = SetpointModeAway
+		setpoint.Temperature = schedule.AwayTemp
+		return
+	case ThermModeFrostGuard:
+		setpoint.Mode = SetpointModeFrostGuard
+		setpoint.Temperature = schedule.HGTemp
+		return
+	}
+	// timetable
+	zone, zoneNext, err := schedule.ZoneAt(t)
+	if err != nil {
+		err = fmt.Errorf("can not evaluate schedule '%s' (%s): %w", schedule.Name, schedule.ID, err)
+		return
+	}
+	consider(zoneNext)
+	temp, found := zone.Setpoints()[roomID]
+	if !found {
+		err = fmt.Errorf("zone '%s' of schedule '%s' (%s) has no temperature for room %s", zone.Name, schedule.Name, schedule.ID, roomID)
+		return
+	}
+	setpoint.Mode = SetpointModeSchedule
+	setpoint.Zone = &zone
+	setpoint.Temperature = temp
+	return
+}
@@ -0,0 +1,535 @@
+package energy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+	https://datatracker.ietf.org/doc/html/rfc5545
+*/
+
+const (
+	icalDateTimeLayout = "20060102T150405"
+	icalDateLayout     = "20060102"
+	icalLineMaxOctets  = 75
+)
+
+var icalDays = [7]string{"MO", "TU", "WE", "TH", "FR", "SA", "SU"}
+
+/*
+	Export
+*/
+
+// WriteICalendar writes the timetable of the schedule as an iCalendar (RFC 5545) file: each timetable entry
+// becomes a weekly recurring event named after its zone, starting in the week of weekOf. Times are floating
+// (wall clock) times as the timetable offsets are, the home time zone is given as the X-WR-TIMEZONE hint.
+func (s Schedule) WriteICalendar(w io.Writer, h Home, weekOf time.Time) (err error) {
+	if len(s.Timetable) == 0 {
+		return fmt.Errorf("timetable is empty")
+	}
+	loc := h.Timezone
+	if loc == nil {
+		loc = time.UTC
+	}
+	weekOf = weekOf.In(loc)
+	weekStart := time.Date(weekOf.Year(), weekOf.Month(), weekOf.Day()-(int(weekOf.Weekday())+6)%7, 0, 0, 0, 0, loc)
+	stamp := time.Now().UTC().Format(icalDateTimeLayout) + "Z"
+	bw := bufio.NewWriter(w)
+	writeLine := func(name, value string) {
+		icalWriteFolded(bw, name+":"+value)
+	}
+	writeLine("BEGIN", "VCALENDAR")
+	writeLine("VERSION", "2.0")
+	writeLine("PRODID", "-//hekmon//go-netatmo//EN")
+	writeLine("CALSCALE", "GREGORIAN")
+	writeLine("X-WR-CALNAME", icalEscape(fmt.Sprintf("%s - %s", h.Name, s.Name)))
+	writeLine("X-WR-TIMEZONE", loc.String())
+	for index, entry := range s.Timetable {
+		zone, found := s.Zone(entry.ZoneID)
+		if !found {
+			return fmt.Errorf("timetable entry #%d: unknown zone ID %d", index, entry.ZoneID)
+		}
+		end := MinutesPerWeek + s.Timetable[0].Offset
+		if index+1 < len(s.Timetable) {
+			end = s.Timetable[index+1].Offset
+		}
+		// description: rooms temperatures
+		setpoints := zone.Setpoints()
+		rooms := make([]string, 0, len(setpoints))
+		for _, room := range h.Rooms {
+			if temp, found := setpoints[room.ID]; found {
+				rooms = append(rooms, fmt.Sprintf("%s: %v°C", room.Name, temp))
+			}
+		}
+		writeLine("BEGIN", "VEVENT")
+		writeLine("UID", fmt.Sprintf("%s-%d@go-netatmo", s.ID, entry.Offset))
+		writeLine("DTSTAMP", stamp)
+		writeLine("DTSTART", offsetTime(weekStart, entry.Offset).Format(icalDateTimeLayout))
+		writeLine("DTEND", offsetTime(weekStart, end).Format(icalDateTimeLayout))
+		writeLine("RRULE", "FREQ=WEEKLY")
+		writeLine("SUMMARY", icalEscape(zone.Name))
+		writeLine("DESCRIPTION", icalEscape(strings.Join(rooms, "\n")))
+		writeLine("CATEGORIES", icalEscape(zone.Type.String()))
+		writeLine("TRANSP", "TRANSPARENT")
+		writeLine("END", "VEVENT")
+	}
+	writeLine("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+func icalWriteFolded(bw *bufio.Writer, line string) {
+	// continuation lines begin with a space which counts in the octets limit
+	limit := icalLineMaxOctets
+	for len(line) > limit {
+		// do not cut an UTF-8 sequence
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		bw.WriteString(line[:cut])
+		bw.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icalLineMaxOctets - 1
+	}
+	bw.WriteString(line)
+	bw.WriteString("\r\n")
+}
+
+var (
+	icalEscaper   = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	icalUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+)
+
+func icalEscape(text string) string {
+	return icalEscaper.Replace(text)
+}
+
+/*
+	Import
+*/
+
+// ICalendarError reports an iCalendar event (or a period of the week) which can not be represented in a timetable
+type ICalendarError struct {
+	Line    int    // line of the event within the file, 0 if the error is not linked to an event
+	UID     string // uid of the event
+	Summary string // summary of the event
+	Reason  string
+}
+
+// Error implements the error interface
+func (ice ICalendarError) Error() string {
+	if ice.Line == 0 {
+		return ice.Reason
+	}
+	return fmt.Sprintf("event '%s' (line %d): %s", ice.Summary, ice.Line, ice.Reason)
+}
+
+// ICalendarErrors contains all the problems found during an import
+type ICalendarErrors []ICalendarError
+
+// Error implements the error interface
+func (ices ICalendarErrors) Error() string {
+	errs := make([]string, len(ices))
+	for index, ice := range ices {
+		errs[index] = ice.Error()
+	}
+	return fmt.Sprintf("%d iCalendar error(s): %s", len(ices), strings.Join(errs, " | "))
+}
+
+// ICalendarImportOptions allows to customize ImportICalendar()
+type ICalendarImportOptions struct {
+	// DefaultZone is the name of the zone used for the periods not covered by any event.
+	// If empty, uncovered periods are reported as errors.
+	DefaultZone string
+}
+
+// ImportICalendar builds a timetable from a constrained iCalendar (RFC 5545) file, as the ones written by
+// WriteICalendar(). Events must be weekly (FREQ=WEEKLY, optionally with BYDAY) or daily recurring events
+// without end, named (SUMMARY) after a zone of template. Floating times are used as is, UTC and TZID times
+// are converted to the home time zone. The returned schedule is template with the new timetable, validated
+// against h. Unrepresentable events, overlaps and gaps are all reported with an ICalendarErrors error.
+func ImportICalendar(r io.Reader, h Home, template Schedule, opts ICalendarImportOptions) (schedule Schedule, err error) {
+	loc := h.Timezone
+	if loc == nil {
+		loc = time.UTC
+	}
+	events, err := icalParseEvents(r)
+	if err != nil {
+		return
+	}
+	// fill the week minute by minute
+	var (
+		week     [MinutesPerWeek]int
+		problems ICalendarErrors
+	)
+	for index := range week {
+		week[index] = -1
+	}
+	for _, event := range events {
+		fail := func(format string, a ...interface{}) {
+			problems = append(problems, ICalendarError{
+				Line:    event.line,
+				UID:     event.value("UID"),
+				Summary: event.value("SUMMARY"),
+				Reason:  fmt.Sprintf(format, a...),
+			})
+		}
+		if strings.EqualFold(event.value("STATUS"), "CANCELLED") {
+			continue
+		}
+		zone, found := icalFindZone(template, event.value("SUMMARY"))
+		if !found {
+			fail("no zone named '%s' in schedule '%s'", event.value("SUMMARY"), template.Name)
+			continue
+		}
+		days, start, duration, reason := event.occurrences(loc)
+		if reason != "" {
+			fail("%s", reason)
+			continue
+		}
+		overlap := -1
+		for _, day := range days {
+			for minute := 0; minute < duration; minute++ {
+				offset := (day*MinutesPerDay + start + minute) % MinutesPerWeek
+				if week[offset] != -1 && week[offset] != zone.ID && overlap == -1 {
+					overlap = offset
+				}
+				week[offset] = zone.ID
+			}
+		}
+		if overlap != -1 {
+			fail("overlaps another event with a different zone at %s", FormatOffset(overlap))
+		}
+	}
+	// gaps
+	if opts.DefaultZone != "" {
+		zone, found := icalFindZone(template, opts.DefaultZone)
+		if !found {
+			err = fmt.Errorf("no default zone named '%s' in schedule '%s'", opts.DefaultZone, template.Name)
+			return
+		}
+		for offset := range week {
+			if week[offset] == -1 {
+				week[offset] = zone.ID
+			}
+		}
+	} else {
+		for offset := 0; offset < MinutesPerWeek; offset++ {
+			if week[offset] != -1 {
+				continue
+			}
+			end := offset
+			for end < MinutesPerWeek && week[end] == -1 {
+				end++
+			}
+			problems = append(problems, ICalendarError{
+				Reason: fmt.Sprintf("period from %s to %s is not covered by any event", FormatOffset(offset), FormatOffset(end%MinutesPerWeek)),
+			})
+			offset = end
+		}
+	}
+	if len(problems) > 0 {
+		err = problems
+		return
+	}
+	// compile
+	schedule = template
+	schedule.Timetable = nil
+	for offset, zoneID := range week {
+		if offset == 0 || zoneID != week[offset-1] {
+			schedule.Timetable = append(schedule.Timetable, TimetableEntry{ZoneID: zoneID, Offset: offset})
+		}
+	}
+	if err = schedule.Validate(h); err != nil {
+		err = fmt.Errorf("invalid schedule: %w", err)
+	}
+	return
+}
+
+func icalFindZone(schedule Schedule, name string) (zone Zone, found bool) {
+	for _, zone = range schedule.Zones {
+		if strings.EqualFold(zone.Name, strings.TrimSpace(name)) {
+			return zone, true
+		}
+	}
+	return Zone{}, false
+}
+
+type icalProperty struct {
+	params map[string]string
+	value  string
+}
+
+type icalEvent struct {
+	line       int
+	properties map[string]icalProperty
+}
+
+func (ie icalEvent) value(name string) string {
+	return ie.properties[name].value
+}
+
+// occurrences returns the days of the week (0 for Monday) the event starts on, its start minute within the
+// day and its duration in minutes. reason is set if the event can not be represented.
+func (ie icalEvent) occurrences(loc *time.Location) (days []int, start, duration int, reason string) {
+	for _, name := range []string{"RDATE", "EXDATE", "EXRULE"} {
+		if _, found := ie.properties[name]; found {
+			return nil, 0, 0, fmt.Sprintf("%s is not supported", name)
+		}
+	}
+	// start and end
+	dtstart, found := ie.properties["DTSTART"]
+	if !found {
+		return nil, 0, 0, "missing DTSTART"
+	}
+	original, err := icalParseTime(dtstart, loc)
+	if err != nil {
+		return nil, 0, 0, fmt.Sprintf("invalid DTSTART: %s", err)
+	}
+	begin := original.In(loc)
+	var end time.Time
+	if dtend, found := ie.properties["DTEND"]; found {
+		if end, err = icalParseTime(dtend, loc); err != nil {
+			return nil, 0, 0, fmt.Sprintf("invalid DTEND: %s", err)
+		}
+		end = end.In(loc)
+	} else if raw, found := ie.properties["DURATION"]; found {
+		var length time.Duration
+		if length, err = icalParseDuration(raw.value); err != nil {
+			return nil, 0, 0, fmt.Sprintf("invalid DURATION: %s", err)
+		}
+		end = begin.Add(length)
+	} else {
+		return nil, 0, 0, "missing DTEND or DURATION"
+	}
+	if begin.Second() != 0 || end.Second() != 0 {
+		return nil, 0, 0, "times must be set on whole minutes"
+	}
+	// wall clock duration (DST safe)
+	beginDate := time.Date(begin.Year(), begin.Month(), begin.Day(), 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	start = begin.Hour()*60 + begin.Minute()
+	duration = int(endDate.Sub(beginDate).Hours()/24)*MinutesPerDay + end.Hour()*60 + end.Minute() - start
+	if duration <= 0 {
+		return nil, 0, 0, "event ends before it starts"
+	}
+	if duration > MinutesPerWeek {
+		return nil, 0, 0, "event lasts more than a week"
+	}
+	// BYDAY is relative to DTSTART in its own zone: days must be shifted if the home local start is on another day
+	originalDate := time.Date(original.Year(), original.Month(), original.Day(), 0, 0, 0, 0, time.UTC)
+	shift := int(beginDate.Sub(originalDate).Hours() / 24)
+	// recurrence
+	rrule, found := ie.properties["RRULE"]
+	if !found {
+		return nil, 0, 0, "non recurring events can not be represented in a weekly timetable"
+	}
+	var (
+		frequency string
+		byDay     []int
+	)
+	for _, part := range strings.Split(rrule.value, ";") {
+		keyValue := strings.SplitN(part, "=", 2)
+		if len(keyValue) != 2 {
+			return nil, 0, 0, fmt.Sprintf("invalid RRULE part '%s'", part)
+		}
+		switch key, value := strings.ToUpper(keyValue[0]), strings.ToUpper(keyValue[1]); key {
+		case "FREQ":
+			frequency = value
+		case "INTERVAL":
+			if value != "1" {
+				return nil, 0, 0, "recurrence interval must be 1"
+			}
+		case "WKST":
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				index := -1
+				for candidate, name := range icalDays {
+					if day == name {
+						index = candidate
+					}
+				}
+				if index == -1 {
+					return nil, 0, 0, fmt.Sprintf("unsupported BYDAY value '%s'", day)
+				}
+				byDay = append(byDay, index)
+			}
+		case "COUNT", "UNTIL":
+			return nil, 0, 0, "recurrences with an end can not be represented in a weekly timetable"
+		default:
+			return nil, 0, 0, fmt.Sprintf("unsupported RRULE part %s", key)
+		}
+	}
+	switch {
+	case frequency == "WEEKLY" && len(byDay) > 0:
+		days = make([]int, len(byDay))
+		for index, day := range byDay {
+			days[index] = ((day+shift)%7 + 7) % 7
+		}
+	case frequency == "WEEKLY":
+		days = []int{(int(begin.Weekday()) + 6) % 7}
+	case frequency == "DAILY" && len(byDay) == 0:
+		days = []int{0, 1, 2, 3, 4, 5, 6}
+	case frequency == "DAILY":
+		return nil, 0, 0, "daily recurrences with BYDAY are not supported"
+	default:
+		return nil, 0, 0, fmt.Sprintf("recurrence frequency '%s' can not be represented in a weekly timetable", frequency)
+	}
+	return
+}
+
+// icalParseTime returns the time in its own zone: UTC, TZID or loc for floating and all day values
+func icalParseTime(property icalProperty, loc *time.Location) (t time.Time, err error) {
+	value := property.value
+	if property.params["VALUE"] == "DATE" || len(value) == len(icalDateLayout) {
+		// all day: midnight (wall clock)
+		return time.ParseInLocation(icalDateLayout, value, loc)
+	}
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse(icalDateTimeLayout, strings.TrimSuffix(value, "Z"))
+	case property.params["TZID"] != "":
+		var tz *time.Location
+		if tz, err = time.LoadLocation(property.params["TZID"]); err != nil {
+			return
+		}
+		return time.ParseInLocation(icalDateTimeLayout, value, tz)
+	default:
+		// floating time
+		return time.ParseInLocation(icalDateTimeLayout, value, loc)
+	}
+}
+
+// icalParseDuration supports the dur-week, dur-date and dur-time forms (for example P1W, P1DT2H, PT30M)
+func icalParseDuration(value string) (duration time.Duration, err error) {
+	if !strings.HasPrefix(value, "P") {
+		return 0, fmt.Errorf("'%s' does not start with P", value)
+	}
+	var (
+		number string
+		inTime bool
+	)
+	for _, char := range value[1:] {
+		if char >= '0' && char <= '9' {
+			number += string(char)
+			continue
+		}
+		if char == 'T' {
+			inTime = true
+			continue
+		}
+		amount, convErr := strconv.Atoi(number)
+		if convErr != nil {
+			return 0, fmt.Errorf("invalid duration '%s'", value)
+		}
+		number = ""
+		switch {
+		case char == 'W' && !inTime:
+			duration += time.Duration(amount) * 7 * 24 * time.Hour
+		case char == 'D' && !inTime:
+			duration += time.Duration(amount) * 24 * time.Hour
+		case char == 'H' && inTime:
+			duration += time.Duration(amount) * time.Hour
+		case char == 'M' && inTime:
+			duration += time.Duration(amount) * time.Minute
+		case char == 'S' && inTime:
+			duration += time.Duration(amount) * time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration '%s'", value)
+		}
+	}
+	if number != "" {
+		return 0, fmt.Errorf("invalid duration '%s'", value)
+	}
+	return
+}
+
+// icalParseEvents unfolds the content lines and extracts the VEVENT components
+func icalParseEvents(r io.Reader) (events []icalEvent, err error) {
+	scanner := bufio.NewScanner(r)
+	var (
+		lines   []string
+		numbers []int
+	)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+		numbers = append(numbers, number)
+	}
+	if err = scanner.Err(); err != nil {
+		err = fmt.Errorf("can not read the iCalendar data: %w", err)
+		return
+	}
+	var (
+		current *icalEvent
+		depth   int // nested components within the event (VALARM)
+	)
+	for index, line := range lines {
+		name, property, parseErr := icalParseLine(line)
+		if parseErr != nil {
+			err = fmt.Errorf("line %d: %w", numbers[index], parseErr)
+			return
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(property.value, "VEVENT"):
+			current = &icalEvent{line: numbers[index], properties: make(map[string]icalProperty)}
+		case current == nil:
+		case name == "BEGIN":
+			depth++
+		case name == "END" && depth > 0:
+			depth--
+		case name == "END" && strings.EqualFold(property.value, "VEVENT"):
+			events = append(events, *current)
+			current = nil
+		case depth == 0:
+			if name == "SUMMARY" || name == "DESCRIPTION" || name == "UID" {
+				property.value = icalUnescaper.Replace(property.value)
+			}
+			if _, found := current.properties[name]; !found {
+				current.properties[name] = property
+			}
+		}
+	}
+	return
+}
+
+// icalParseLine splits a content line into its name, parameters and value
+func icalParseLine(line string) (name string, property icalProperty, err error) {
+	inQuotes := false
+	colon := -1
+	for index, char := range line {
+		if char == '"' {
+			inQuotes = !inQuotes
+		} else if char == ':' && !inQuotes {
+			colon = index
+			break
+		}
+	}
+	if colon == -1 {
+		err = fmt.Errorf("invalid content line '%s'", line)
+		return
+	}
+	property.value = line[colon+1:]
+	parts := strings.Split(line[:colon], ";")
+	name = strings.ToUpper(parts[0])
+	property.params = make(map[string]string, len(parts)-1)
+	for _, param := range parts[1:] {
+		keyValue := strings.SplitN(param, "=", 2)
+		if len(keyValue) == 2 {
+			property.params[strings.ToUpper(keyValue[0])] = strings.Trim(keyValue[1], `"`)
+		}
+	}
+	return
+}
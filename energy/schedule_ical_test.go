@@ -0,0 +1,46 @@
+package energy
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestICalendarOccurrencesZoneShift(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skipf("time zone database not available: %s", err)
+	}
+	for _, tc := range []struct {
+		dtstart icalProperty
+		byDay   string
+		days    []int
+		start   int
+	}{
+		// same day in both zones
+		{icalProperty{value: "20260105T203000Z"}, "MO,WE", []int{0, 2}, 21*60 + 30},
+		// the home local start is on the next day
+		{icalProperty{value: "20260105T233000Z"}, "MO", []int{1}, 30},
+		{icalProperty{value: "20260105T233000Z"}, "SU,MO", []int{0, 1}, 30},
+		{icalProperty{params: map[string]string{"TZID": "America/New_York"}, value: "20260105T200000"}, "MO,FR", []int{1, 5}, 2 * 60},
+		// the home local start is on the previous day
+		{icalProperty{params: map[string]string{"TZID": "Asia/Tokyo"}, value: "20260105T060000"}, "MO", []int{6}, 22 * 60},
+		// floating times are already in the home zone
+		{icalProperty{value: "20260105T233000"}, "MO", []int{0}, 23*60 + 30},
+	} {
+		event := icalEvent{properties: map[string]icalProperty{
+			"DTSTART":  tc.dtstart,
+			"DURATION": {value: "PT1H"},
+			"RRULE":    {value: "FREQ=WEEKLY;BYDAY=" + tc.byDay},
+		}}
+		days, start, duration, reason := event.occurrences(paris)
+		if reason != "" {
+			t.Errorf("%s %v: unexpected reason: %s", tc.dtstart.value, tc.dtstart.params, reason)
+			continue
+		}
+		if fmt.Sprint(days) != fmt.Sprint(tc.days) || start != tc.start || duration != 60 {
+			t.Errorf("%s %v BYDAY=%s: expected days %v at %d for 60 minutes, got days %v at %d for %d minutes",
+				tc.dtstart.value, tc.dtstart.params, tc.byDay, tc.days, tc.start, days, start, duration)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package schedulesync keeps the heating schedules of energy homes in sync with declarative files (YAML or JSON).
+// A file is compared with the live schedules to compute a Plan, which can then be applied with a Syncer.
+//
+//	homes:
+//	  - home: My home              # name or ID of the home
+//	    schedules:
+//	      - name: Winter           # name of an existing schedule of the home
+//	        select: true           # switch the home to this schedule
+//	        away_temp: 12
+//	        hg_temp: 7
+//	        zones:
+//	          - name: Comfort
+//	            type: comfort      # day, night, custom, eco or comfort
+//	            default: 20        # temperature of the rooms not listed
+//	            rooms:
+//	              Bathroom: 22     # room name or ID
+//	          - name: Night
+//	            type: night
+//	            default: 17
+//	        week:
+//	          - weekdays 06:30 Comfort, 22:00 Night
+//	          - weekend 08:00 Comfort, 23:00 Night
+package schedulesync
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hekmon/go-netatmo/energy"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the declarative description of the schedules of one or several homes
+type File struct {
+	Homes []HomeSpec `yaml:"homes" json:"homes"`
+}
+
+// HomeSpec describes the schedules of a home
+type HomeSpec struct {
+	Home      string         `yaml:"home" json:"home"` // name or ID of the home
+	Schedules []ScheduleSpec `yaml:"schedules" json:"schedules"`
+}
+
+// ScheduleSpec describes a weekly schedule. Week lines use the energy.ScheduleBuilder Program() syntax.
+type ScheduleSpec struct {
+	Name     string     `yaml:"name" json:"name"`                               // name of the existing schedule
+	Select   bool       `yaml:"select,omitempty" json:"select,omitempty"`       // the home should use this schedule
+	AwayTemp *float64   `yaml:"away_temp,omitempty" json:"away_temp,omitempty"` // current value kept if not set
+	HGTemp   *float64   `yaml:"hg_temp,omitempty" json:"hg_temp,omitempty"`     // current value kept if not set
+	Zones    []ZoneSpec `yaml:"zones" json:"zones"`
+	Week     []string   `yaml:"week" json:"week"`
+}
+
+// ZoneSpec describes a zone of a schedule
+type ZoneSpec struct {
+	Name    string             `yaml:"name" json:"name"`
+	Type    string             `yaml:"type" json:"type"`                           // zone type name (see energy.ZoneType String()) or value
+	Default float64            `yaml:"default,omitempty" json:"default,omitempty"` // temperature of the rooms not listed
+	Rooms   map[string]float64 `yaml:"rooms,omitempty" json:"rooms,omitempty"`     // room name or ID -> temperature
+}
+
+// zoneType returns the energy.ZoneType matching the spec type
+func (zs ZoneSpec) zoneType() (zoneType energy.ZoneType, err error) {
+	if value, convErr := strconv.Atoi(zs.Type); convErr == nil {
+		return energy.ZoneType(value), nil
+	}
+	name := strings.ToLower(strings.Replace(strings.TrimSpace(zs.Type), "_", " ", -1))
+	for _, zoneType = range []energy.ZoneType{energy.ZoneTypeDay, energy.ZoneTypeNight, energy.ZoneTypeAway,
+		energy.ZoneTypeFrostGuard, energy.ZoneTypeCustom, energy.ZoneTypeEco, energy.ZoneTypeComfort} {
+		if zoneType.String() == name {
+			return
+		}
+	}
+	return 0, fmt.Errorf("unknown zone type '%s'", zs.Type)
+}
+
+// Load decodes a schedule file. As YAML is a superset of JSON, both formats are accepted.
+func Load(r io.Reader) (file File, err error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err = decoder.Decode(&file); err != nil {
+		err = fmt.Errorf("can not decode the schedule file: %w", err)
+	}
+	return
+}
+
+// LoadFile decodes the schedule file at path
+func LoadFile(path string) (file File, err error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		err = fmt.Errorf("can not open the schedule file: %w", err)
+		return
+	}
+	defer fd.Close()
+	return Load(fd)
+}
@@ -0,0 +1,263 @@
+package schedulesync
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/hekmon/go-netatmo/energy"
+)
+
+// Change describes the actions needed to bring a live schedule to its declared state
+type Change struct {
+	Home        energy.Home
+	Current     energy.Schedule // live schedule
+	Desired     energy.Schedule // schedule built from the file (same ID as Current)
+	Sync        bool            // the timetable, the zones or the temperatures differ: synchomeschedule is needed
+	Switch      bool            // the home must be switched to this schedule
+	Differences []string        // human readable differences between Current and Desired
+}
+
+// Plan lists the changes computed from a file, one per declared schedule (up to date schedules included)
+type Plan struct {
+	Changes []Change
+}
+
+// Empty returns true if every declared schedule is up to date
+func (p Plan) Empty() bool {
+	for _, change := range p.Changes {
+		if change.Sync || change.Switch {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteTo writes a human readable version of the plan to w (implements https://golang.org/pkg/io/#WriterTo)
+func (p Plan) WriteTo(w io.Writer) (n int64, err error) {
+	var buffer bytes.Buffer
+	var homeID string
+	for _, change := range p.Changes {
+		if change.Home.ID != homeID {
+			homeID = change.Home.ID
+			fmt.Fprintf(&buffer, "home '%s' (%s):\n", change.Home.Name, change.Home.ID)
+		}
+		var actions []string
+		if change.Sync {
+			actions = append(actions, "sync")
+		}
+		if change.Switch {
+			actions = append(actions, "switch")
+		}
+		if len(actions) == 0 {
+			actions = append(actions, "up to date")
+		}
+		fmt.Fprintf(&buffer, "\tschedule '%s' (%s): %s\n", change.Desired.Name, change.Current.ID, strings.Join(actions, " + "))
+		for _, difference := range change.Differences {
+			fmt.Fprintf(&buffer, "\t\t%s\n", difference)
+		}
+	}
+	return buffer.WriteTo(w)
+}
+
+// NewPlan compares file with homes (from energy.Client.GetHomesData()). Declared homes and schedules must exist.
+func NewPlan(homes []energy.Home, file File) (plan Plan, err error) {
+	for _, homeSpec := range file.Homes {
+		home, found := findHome(homes, homeSpec.Home)
+		if !found {
+			err = fmt.Errorf("home '%s' not found", homeSpec.Home)
+			return
+		}
+		var selected string
+		for _, scheduleSpec := range homeSpec.Schedules {
+			var change Change
+			if change, err = newChange(home, scheduleSpec); err != nil {
+				err = fmt.Errorf("home '%s': schedule '%s': %w", home.Name, scheduleSpec.Name, err)
+				return
+			}
+			if scheduleSpec.Select {
+				if selected != "" {
+					err = fmt.Errorf("home '%s': schedules '%s' and '%s' can not be both selected", home.Name, selected, scheduleSpec.Name)
+					return
+				}
+				selected = scheduleSpec.Name
+			}
+			plan.Changes = append(plan.Changes, change)
+		}
+	}
+	return
+}
+
+func newChange(home energy.Home, spec ScheduleSpec) (change Change, err error) {
+	change.Home = home
+	var found bool
+	if change.Current, found = findSchedule(home, spec.Name); !found {
+		err = fmt.Errorf("schedule not found: it must be created from the Netatmo application first")
+		return
+	}
+	// build
+	builder := energy.NewScheduleBuilder(home, change.Current.ID, change.Current.Name)
+	if spec.AwayTemp != nil {
+		builder.AwayTemp(*spec.AwayTemp)
+	}
+	if spec.HGTemp != nil {
+		builder.FrostGuardTemp(*spec.HGTemp)
+	}
+	for _, zoneSpec := range spec.Zones {
+		var zoneType energy.ZoneType
+		if zoneType, err = zoneSpec.zoneType(); err != nil {
+			err = fmt.Errorf("zone '%s': %w", zoneSpec.Name, err)
+			return
+		}
+		zone := builder.Zone(zoneSpec.Name, zoneType, zoneSpec.Default)
+		rooms := make([]string, 0, len(zoneSpec.Rooms))
+		for room := range zoneSpec.Rooms {
+			rooms = append(rooms, room)
+		}
+		sort.Strings(rooms)
+		for _, nameOrID := range rooms {
+			room, found := findRoom(home, nameOrID)
+			if !found {
+				err = fmt.Errorf("zone '%s': room '%s' not found", zoneSpec.Name, nameOrID)
+				return
+			}
+			zone.Room(room.ID, zoneSpec.Rooms[nameOrID])
+		}
+	}
+	for _, line := range spec.Week {
+		builder.Program(line)
+	}
+	if change.Desired, err = builder.Build(); err != nil {
+		return
+	}
+	change.Desired.Selected = spec.Select || change.Current.Selected
+	// compare
+	change.Differences = differences(home, change.Current, change.Desired)
+	change.Sync = len(change.Differences) > 0
+	change.Switch = spec.Select && !change.Current.Selected
+	return
+}
+
+// differences compares the schedules semantically: zones are matched by name and timetables by active zone.
+// Schedules are matched by name so the names never differ.
+func differences(home energy.Home, current, desired energy.Schedule) (diffs []string) {
+	if current.AwayTemp != desired.AwayTemp {
+		diffs = append(diffs, fmt.Sprintf("away temperature: %v°C -> %v°C", current.AwayTemp, desired.AwayTemp))
+	}
+	if current.HGTemp != desired.HGTemp {
+		diffs = append(diffs, fmt.Sprintf("frost guard temperature: %v°C -> %v°C", current.HGTemp, desired.HGTemp))
+	}
+	// zones
+	for _, desiredZone := range desired.Zones {
+		currentZone, found := findZone(current, desiredZone.Name)
+		if !found {
+			diffs = append(diffs, fmt.Sprintf("zone '%s': added", desiredZone.Name))
+			continue
+		}
+		if currentZone.Type != desiredZone.Type {
+			diffs = append(diffs, fmt.Sprintf("zone '%s': type %s -> %s", desiredZone.Name, currentZone.Type, desiredZone.Type))
+		}
+		currentSetpoints := currentZone.Setpoints()
+		desiredSetpoints := desiredZone.Setpoints()
+		for _, room := range home.Rooms {
+			currentTemp, currentFound := currentSetpoints[room.ID]
+			desiredTemp := desiredSetpoints[room.ID]
+			switch {
+			case !currentFound:
+				diffs = append(diffs, fmt.Sprintf("zone '%s': room '%s': none -> %v°C", desiredZone.Name, room.Name, desiredTemp))
+			case currentTemp != desiredTemp:
+				diffs = append(diffs, fmt.Sprintf("zone '%s': room '%s': %v°C -> %v°C", desiredZone.Name, room.Name, currentTemp, desiredTemp))
+			}
+		}
+	}
+	for _, currentZone := range current.Zones {
+		if _, found := findZone(desired, currentZone.Name); !found {
+			diffs = append(diffs, fmt.Sprintf("zone '%s': removed", currentZone.Name))
+		}
+	}
+	// timetable
+	offsets := make(map[int]bool, len(current.Timetable)+len(desired.Timetable))
+	for _, entry := range current.Timetable {
+		offsets[entry.Offset] = true
+	}
+	for _, entry := range desired.Timetable {
+		offsets[entry.Offset] = true
+	}
+	sorted := make([]int, 0, len(offsets))
+	for offset := range offsets {
+		sorted = append(sorted, offset)
+	}
+	sort.Ints(sorted)
+	for _, offset := range sorted {
+		currentName := zoneNameAt(current, offset)
+		desiredName := zoneNameAt(desired, offset)
+		if !strings.EqualFold(currentName, desiredName) {
+			diffs = append(diffs, fmt.Sprintf("timetable from %s: %s -> %s", energy.FormatOffset(offset), currentName, desiredName))
+		}
+	}
+	return
+}
+
+// zoneNameAt returns the name of the zone active at offset
+func zoneNameAt(schedule energy.Schedule, offset int) string {
+	if len(schedule.Timetable) == 0 {
+		return "none"
+	}
+	index := sort.Search(len(schedule.Timetable), func(i int) bool {
+		return schedule.Timetable[i].Offset > offset
+	}) - 1
+	if index < 0 {
+		index = len(schedule.Timetable) - 1
+	}
+	zone, found := schedule.Zone(schedule.Timetable[index].ZoneID)
+	if !found {
+		return fmt.Sprintf("<unknown zone %d>", schedule.Timetable[index].ZoneID)
+	}
+	return zone.Name
+}
+
+func findHome(homes []energy.Home, nameOrID string) (home energy.Home, found bool) {
+	for _, home = range homes {
+		if home.ID == nameOrID {
+			return home, true
+		}
+	}
+	for _, home = range homes {
+		if strings.EqualFold(home.Name, nameOrID) {
+			return home, true
+		}
+	}
+	return energy.Home{}, false
+}
+
+func findSchedule(home energy.Home, name string) (schedule energy.Schedule, found bool) {
+	for _, schedule = range home.Schedules {
+		if strings.EqualFold(schedule.Name, name) && (schedule.Type == "" || schedule.Type == "therm") {
+			return schedule, true
+		}
+	}
+	return energy.Schedule{}, false
+}
+
+func findRoom(home energy.Home, nameOrID string) (room energy.Room, found bool) {
+	if room, found = home.Room(energy.RoomID(nameOrID)); found {
+		return
+	}
+	for _, room = range home.Rooms {
+		if strings.EqualFold(room.Name, nameOrID) {
+			return room, true
+		}
+	}
+	return energy.Room{}, false
+}
+
+func findZone(schedule energy.Schedule, name string) (zone energy.Zone, found bool) {
+	for _, zone = range schedule.Zones {
+		if strings.EqualFold(zone.Name, name) {
+			return zone, true
+		}
+	}
+	return energy.Zone{}, false
+}
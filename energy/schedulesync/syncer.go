@@ -0,0 +1,72 @@
+package schedulesync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hekmon/go-netatmo/energy"
+)
+
+// Config allows to customize the Syncer
+type Config struct {
+	DryRun bool // compute the plans but never call the write endpoints
+}
+
+// Syncer computes and applies plans against the live homes
+type Syncer struct {
+	client *energy.Client
+	conf   Config
+}
+
+// New returns a syncer using client for the API calls
+func New(client *energy.Client, conf Config) *Syncer {
+	return &Syncer{
+		client: client,
+		conf:   conf,
+	}
+}
+
+// Plan retrieves the live homes and compares them with file
+func (s *Syncer) Plan(ctx context.Context, file File) (plan Plan, err error) {
+	data, _, _, err := s.client.GetHomesData(ctx, energy.GetHomesDataParameters{})
+	if err != nil {
+		err = fmt.Errorf("can not retrieve the homes data: %w", err)
+		return
+	}
+	return NewPlan(data.Homes, file)
+}
+
+// Result reports what has been done for a change of the plan
+type Result struct {
+	Change   Change
+	Synced   bool // the schedule has been modified by synchomeschedule
+	Switched bool // the home has been switched to the schedule by switchhomeschedule
+}
+
+// Apply executes the plan: schedules are synced first, then homes are switched to their selected schedule.
+// Up to date schedules are left untouched, which makes successive applies of the same file idempotent.
+// In dry run mode nothing is sent and every result reports no modification. On error, the results already
+// obtained are returned along with the error.
+func (s *Syncer) Apply(ctx context.Context, plan Plan) (results []Result, err error) {
+	results = make([]Result, len(plan.Changes))
+	for index, change := range plan.Changes {
+		results[index].Change = change
+		if !change.Sync || s.conf.DryRun {
+			continue
+		}
+		if results[index].Synced, _, _, err = s.client.SyncHomeSchedule(ctx, change.Home, change.Desired); err != nil {
+			err = fmt.Errorf("can not sync schedule '%s' of home '%s': %w", change.Desired.Name, change.Home.Name, err)
+			return
+		}
+	}
+	for index, change := range plan.Changes {
+		if !change.Switch || s.conf.DryRun {
+			continue
+		}
+		if results[index].Switched, _, _, err = s.client.SwitchHomeSchedule(ctx, change.Home.ID, change.Current.ID); err != nil {
+			err = fmt.Errorf("can not switch home '%s' to schedule '%s': %w", change.Home.Name, change.Desired.Name, err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,162 @@
+package energy
+
+import "sort"
+
+// Topology is the resolved graph of a home: rooms know their modules, modules know their room and their
+// relay, relays know the modules they bridge. Links are built from both sides of the payload (room module_ids,
+// module room_id, module bridge and relay modules_bridged) so a link declared only once is still resolved.
+type Topology struct {
+	Home    Home
+	Rooms   map[RoomID]*TopologyRoom
+	Modules map[string]*TopologyModule
+	// Unresolved contains the IDs (rooms or modules) referenced by the payload but not declared in it
+	Unresolved []string
+}
+
+// TopologyRoom is a room along with its resolved modules
+type TopologyRoom struct {
+	Room    Room
+	Modules []*TopologyModule // sorted by ID
+}
+
+// HasValve returns true if at least one valve is installed in the room
+func (tr *TopologyRoom) HasValve() bool {
+	for _, module := range tr.Modules {
+		if module.Module.Type == ModuleTypeValve {
+			return true
+		}
+	}
+	return false
+}
+
+// Thermostat returns the thermostat of the room, if any
+func (tr *TopologyRoom) Thermostat() *TopologyModule {
+	for _, module := range tr.Modules {
+		if module.Module.Type == ModuleTypeThermostat {
+			return module
+		}
+	}
+	return nil
+}
+
+// TopologyModule is a module along with its resolved relations
+type TopologyModule struct {
+	Module  Module
+	Room    *TopologyRoom     // nil for relays or unassigned modules
+	Bridge  *TopologyModule   // relay the module is connected to, nil for relays
+	Bridged []*TopologyModule // relays only: modules connected to the relay, sorted by ID
+}
+
+// Topology resolves the relations between the rooms and the modules of the home
+func (h Home) Topology() (topology *Topology) {
+	topology = &Topology{
+		Home:    h,
+		Rooms:   make(map[RoomID]*TopologyRoom, len(h.Rooms)),
+		Modules: make(map[string]*TopologyModule, len(h.Modules)),
+	}
+	unresolved := make(map[string]bool)
+	for _, room := range h.Rooms {
+		topology.Rooms[room.ID] = &TopologyRoom{Room: room}
+	}
+	for _, module := range h.Modules {
+		topology.Modules[module.ID] = &TopologyModule{Module: module}
+	}
+	// room <-> module (a module already placed in a room is not moved)
+	link := func(room *TopologyRoom, module *TopologyModule) {
+		if module.Room != nil {
+			return
+		}
+		module.Room = room
+		room.Modules = append(room.Modules, module)
+	}
+	for _, r := range h.Rooms {
+		room := topology.Rooms[r.ID]
+		for _, moduleID := range r.ModuleIDs {
+			if module, found := topology.Modules[moduleID]; found {
+				link(room, module)
+			} else {
+				unresolved[moduleID] = true
+			}
+		}
+	}
+	for _, m := range h.Modules {
+		module := topology.Modules[m.ID]
+		if m.RoomID == "" {
+			continue
+		}
+		if room, found := topology.Rooms[m.RoomID]; found {
+			link(room, module)
+		} else {
+			unresolved[string(m.RoomID)] = true
+		}
+	}
+	// relay <-> module (a module already bridged is not moved)
+	bridge := func(relay, module *TopologyModule) {
+		if module.Bridge != nil {
+			return
+		}
+		module.Bridge = relay
+		relay.Bridged = append(relay.Bridged, module)
+	}
+	for _, m := range h.Modules {
+		relay := topology.Modules[m.ID]
+		for _, moduleID := range m.ModulesBridged {
+			if module, found := topology.Modules[moduleID]; found {
+				bridge(relay, module)
+			} else {
+				unresolved[moduleID] = true
+			}
+		}
+	}
+	for _, m := range h.Modules {
+		if m.Bridge == "" {
+			continue
+		}
+		if relay, found := topology.Modules[m.Bridge]; found {
+			bridge(relay, topology.Modules[m.ID])
+		} else {
+			unresolved[m.Bridge] = true
+		}
+	}
+	// stable order
+	for _, room := range topology.Rooms {
+		sortTopologyModules(room.Modules)
+	}
+	for _, module := range topology.Modules {
+		sortTopologyModules(module.Bridged)
+	}
+	topology.Unresolved = make([]string, 0, len(unresolved))
+	for id := range unresolved {
+		topology.Unresolved = append(topology.Unresolved, id)
+	}
+	sort.Strings(topology.Unresolved)
+	return
+}
+
+// Relays returns the relays of the home, sorted by ID
+func (t *Topology) Relays() (relays []*TopologyModule) {
+	for _, module := range t.Modules {
+		if module.Module.Type == ModuleTypeRelay {
+			relays = append(relays, module)
+		}
+	}
+	sortTopologyModules(relays)
+	return
+}
+
+// Unassigned returns the thermostats and valves which are not placed in any room, sorted by ID
+func (t *Topology) Unassigned() (modules []*TopologyModule) {
+	for _, module := range t.Modules {
+		if module.Module.Type != ModuleTypeRelay && module.Room == nil {
+			modules = append(modules, module)
+		}
+	}
+	sortTopologyModules(modules)
+	return
+}
+
+func sortTopologyModules(modules []*TopologyModule) {
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Module.ID < modules[j].Module.ID
+	})
+}
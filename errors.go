@@ -1,6 +1,7 @@
 package netatmo
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -71,6 +72,28 @@ func (hsoe HTTPStatusOKError) Error() string {
 	return fmt.Sprintf("error code %d ('%s') for device '%s'", hsoe.Code, statusOKErrors[hsoe.Code], hsoe.DeviceID)
 }
 
+// ErrorCodeNothingToModify is the error code returned when a write request does not change anything
+const ErrorCodeNothingToModify = 23
+
+// IsNothingToModify returns true if err only reports that the request did not change anything
+// (the requested state was already the current one)
+func IsNothingToModify(err error) bool {
+	var hsoes HTTPStatusOKErrors
+	if errors.As(err, &hsoes) {
+		for _, hsoe := range hsoes {
+			if hsoe.Code != ErrorCodeNothingToModify {
+				return false
+			}
+		}
+		return len(hsoes) > 0
+	}
+	var hsge HTTPStatusGenericError
+	if errors.As(err, &hsge) {
+		return hsge.NetatmoCode == ErrorCodeNothingToModify
+	}
+	return false
+}
+
 // UnexpectedHTTPCode will be used for any unexpected HTTP error codes
 type UnexpectedHTTPCode struct {
 	HTTPCode int
@@ -0,0 +1,79 @@
+// Package cliauth creates the authenticated clients used by the command line tools.
+//
+// At first start, tokens are retrieved with the client credentials workflow (NETATMO_USERNAME and
+// NETATMO_PASSWORD env vars) and saved to the tokens file. Next starts only need the tokens file.
+// NETATMO_CLIENT_ID and NETATMO_CLIENT_SECRET env vars must always be set.
+package cliauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/hekmon/go-netatmo"
+	"golang.org/x/oauth2"
+)
+
+// NewClient restores the client from the tokens file or creates it using credentials if the file does not exist.
+// Tokens are saved to the tokens file at creation and each time they are refreshed.
+func NewClient(ctx context.Context, tokensFile string, scopes ...string) (client netatmo.AuthenticatedClient, err error) {
+	oac := netatmo.GenerateOAuth2Config(netatmo.OAuth2BaseConfig{
+		ClientID:     os.Getenv("NETATMO_CLIENT_ID"),
+		ClientSecret: os.Getenv("NETATMO_CLIENT_SECRET"),
+		Scopes:       scopes,
+		RedirectURL:  os.Getenv("NETATMO_REDIRECT_URL"),
+	})
+	// Try to restore previous tokens
+	data, err := ioutil.ReadFile(tokensFile)
+	switch {
+	case err == nil:
+		var tokens oauth2.Token
+		if err = json.Unmarshal(data, &tokens); err != nil {
+			err = fmt.Errorf("can not parse tokens file '%s': %w", tokensFile, err)
+			return
+		}
+		if client, err = netatmo.NewClientWithTokens(ctx, oac, &tokens, nil); err != nil {
+			return
+		}
+	case errors.Is(err, os.ErrNotExist):
+		// First start, use credentials
+		username := os.Getenv("NETATMO_USERNAME")
+		password := os.Getenv("NETATMO_PASSWORD")
+		if username == "" || password == "" {
+			err = fmt.Errorf("tokens file '%s' does not exist: NETATMO_USERNAME and NETATMO_PASSWORD must be set", tokensFile)
+			return
+		}
+		if client, err = netatmo.NewClientWithClientCredentials(ctx, oac, username, password, nil); err != nil {
+			return
+		}
+		if err = saveTokens(tokensFile, client.GetTokens()); err != nil {
+			err = fmt.Errorf("can not save the oauth2 tokens: %w", err)
+			return
+		}
+	default:
+		err = fmt.Errorf("can not read tokens file '%s': %w", tokensFile, err)
+		return
+	}
+	// Save the tokens as soon as they are refreshed
+	if controller, ok := client.(*netatmo.Controller); ok {
+		controller.OnTokensRefresh(func(tokens oauth2.Token) {
+			if err := saveTokens(tokensFile, tokens); err != nil {
+				log.Printf("can not save the refreshed oauth2 tokens: %s", err)
+			}
+		})
+	}
+	return
+}
+
+// saveTokens writes the tokens to the tokens file
+func saveTokens(tokensFile string, tokens oauth2.Token) (err error) {
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return
+	}
+	return ioutil.WriteFile(tokensFile, data, 0600)
+}
@@ -0,0 +1,99 @@
+// Package measures parses the measures returned by the getmeasure like endpoints of the weather and energy APIs.
+package measures
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Point represents the values of a series at a given time. A nil value means no data.
+type Point struct {
+	Time   time.Time
+	Values []*float64
+}
+
+// Points is a collection of Point
+type Points []Point
+
+// Len returns the number of values (implements the https://pkg.go.dev/sort#Interface)
+func (mps Points) Len() int {
+	return len(mps)
+}
+
+// Less returns true if the timestamp of i is before j (implements the https://pkg.go.dev/sort#Interface)
+func (mps Points) Less(i, j int) bool {
+	return mps[i].Time.Before(mps[j].Time)
+}
+
+// Swap changes the values places between them within the array (implements the https://pkg.go.dev/sort#Interface)
+func (mps Points) Swap(i, j int) {
+	mps[i], mps[j] = mps[j], mps[i]
+}
+
+type optimizedMeasures struct {
+	BeginTime int64        `json:"beg_time"`
+	StepTime  int64        `json:"step_time"`
+	Values    [][]*float64 `json:"value"`
+}
+
+// Unmarshal parses the body of the getmeasure like endpoints (weather getmeasure, energy getroommeasure
+// and getmeasure), in both their optimized and not optimized formats. nbTypes is the number of requested measure types.
+func Unmarshal(data json.RawMessage, nbTypes int) (points Points, err error) {
+	if len(data) == 0 || string(data) == "null" {
+		return
+	}
+	switch data[0] {
+	case '[':
+		// optimized format: chunks of values at regular interval
+		var chunks []optimizedMeasures
+		if err = json.Unmarshal(data, &chunks); err != nil {
+			err = fmt.Errorf("failed to unmarshall optimized measures: %w", err)
+			return
+		}
+		for _, chunk := range chunks {
+			for index, values := range chunk.Values {
+				if len(values) != nbTypes {
+					err = fmt.Errorf("unexpected number of values (%d, expecting %d) at index %d of chunk beginning at %d",
+						len(values), nbTypes, index, chunk.BeginTime)
+					return
+				}
+				points = append(points, Point{
+					Time:   time.Unix(chunk.BeginTime+int64(index)*chunk.StepTime, 0),
+					Values: values,
+				})
+			}
+		}
+	case '{':
+		// not optimized format: values by timestamp
+		var measures map[string][]*float64
+		if err = json.Unmarshal(data, &measures); err != nil {
+			err = fmt.Errorf("failed to unmarshall measures: %w", err)
+			return
+		}
+		var timestamp int64
+		points = make(Points, 0, len(measures))
+		for timestampStr, values := range measures {
+			if len(values) != nbTypes {
+				err = fmt.Errorf("unexpected number of values (%d, expecting %d) for timestamp %s: %v",
+					len(values), nbTypes, timestampStr, values)
+				return
+			}
+			if timestamp, err = strconv.ParseInt(timestampStr, 10, 64); err != nil {
+				err = fmt.Errorf("can not convert '%s' timestamp as integer: %w", timestampStr, err)
+				return
+			}
+			points = append(points, Point{
+				Time:   time.Unix(timestamp, 0),
+				Values: values,
+			})
+		}
+	default:
+		err = fmt.Errorf("unexpected measures payload: %s", string(data))
+		return
+	}
+	sort.Sort(points)
+	return
+}
@@ -0,0 +1,206 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	defaultAnomalyRadius               = 5 // km
+	defaultAnomalyMinNeighbours        = 3
+	defaultAnomalyTemperatureThreshold = 2  // °C
+	defaultAnomalyHumidityThreshold    = 10 // %
+	defaultAnomalyPressureThreshold    = 2  // mbar
+)
+
+// AnomalyConfig allows to customize the comparison of user stations against their public neighbours.
+// Zero values are replaced by sane defaults.
+type AnomalyConfig struct {
+	Radius               float64         // neighbourhood radius in kilometers (default 5)
+	MinNeighbours        int             // minimum number of neighbours needed to compare a measure (default 3)
+	TemperatureThreshold float64         // deviation from the baseline flagged as an anomaly, in °C (default 2)
+	HumidityThreshold    float64         // deviation from the baseline flagged as an anomaly, in % (default 10)
+	PressureThreshold    float64         // deviation from the baseline flagged as an anomaly, in mbar (default 2)
+	Aggregate            AggregateConfig // used to compute the neighbourhood baseline, its Now and MaxAge also apply to our own readings
+}
+
+// MeasureComparison is the comparison of one of our readings against the neighbourhood baseline
+type MeasureComparison struct {
+	Value      float64   // our reading
+	Time       time.Time // time of our reading
+	Baseline   Aggregate // robust statistics of the neighbours readings
+	Spread     float64   // robust standard deviation of the neighbours readings (1.4826 * MAD)
+	Deviation  float64   // Value - Baseline.Median
+	Anomaly    bool      // true if the absolute deviation exceeds the configured threshold
+	Confidence float64   // 0 to 1: how much the baseline can be trusted (number of neighbours and their agreement)
+	// CalibrationOffset is the suggested offset to add to our readings to match the baseline (-Deviation).
+	// It should only be applied after the anomaly has been confirmed over time with a high confidence.
+	CalibrationOffset float64
+}
+
+// StationComparison holds the comparisons of a user station against its public neighbours.
+// Nil comparisons indicate that the measure could not be compared (no recent reading or not enough neighbours).
+type StationComparison struct {
+	StationID       string
+	StationName     string
+	OutdoorModuleID string // empty if the station has no reachable outdoor module
+	Neighbours      int    // public stations found within the radius (our own station excluded)
+	Temperature     *MeasureComparison
+	Humidity        *MeasureComparison
+	Pressure        *MeasureComparison
+}
+
+// Anomalies returns the names of the measures flagged as anomalies
+func (sc StationComparison) Anomalies() (measures []string) {
+	if sc.Temperature != nil && sc.Temperature.Anomaly {
+		measures = append(measures, "temperature")
+	}
+	if sc.Humidity != nil && sc.Humidity.Anomaly {
+		measures = append(measures, "humidity")
+	}
+	if sc.Pressure != nil && sc.Pressure.Anomaly {
+		measures = append(measures, "pressure")
+	}
+	return
+}
+
+// CompareWithNeighbours queries the public stations around each of the user stations (see GetStationData())
+// and compares our outdoor and pressure readings with them. Stations without location are skipped.
+func (wc *Client) CompareWithNeighbours(ctx context.Context, data StationDataBody, conf AnomalyConfig) (comparisons []StationComparison, err error) {
+	conf = conf.withDefaults()
+	comparisons = make([]StationComparison, 0, len(data.Devices))
+	for _, station := range data.Devices {
+		center, ok := station.Place.Point()
+		if !ok {
+			continue
+		}
+		var neighbours []PublicStationDistance
+		if neighbours, err = wc.GetPublicDataRadius(ctx, GetPublicDataRadiusParameters{
+			Center: center,
+			Radius: conf.Radius,
+			Filter: true,
+		}); err != nil {
+			err = fmt.Errorf("failed to get the neighbours of station '%s': %w", station.ID, err)
+			return
+		}
+		publicStations := make([]PublicStationData, len(neighbours))
+		for index, neighbour := range neighbours {
+			publicStations[index] = neighbour.PublicStationData
+		}
+		comparisons = append(comparisons, CompareStation(station, publicStations, conf))
+	}
+	return
+}
+
+// CompareStation compares the readings of a user station with the given public stations.
+// The public station matching the user station ID (if it is shared publicly) is ignored.
+func CompareStation(station StationDataBodyDevices, neighbours []PublicStationData, conf AnomalyConfig) (comparison StationComparison) {
+	conf = conf.withDefaults()
+	comparison = StationComparison{
+		StationID:   station.ID,
+		StationName: station.ModuleName,
+	}
+	// gather neighbours readings
+	oldest := conf.Aggregate.Now.Add(-conf.Aggregate.MaxAge)
+	var temperature, humidity, pressure []float64
+	for _, neighbour := range neighbours {
+		if neighbour.ID == station.ID {
+			continue
+		}
+		comparison.Neighbours++
+		if len(neighbour.Pressure) > 0 {
+			if last := neighbour.Pressure[len(neighbour.Pressure)-1]; !last.Time.Before(oldest) {
+				pressure = append(pressure, last.Pressure)
+			}
+		}
+		if neighbour.Outdoor != nil && len(neighbour.Outdoor.Measures) > 0 {
+			if last := neighbour.Outdoor.Measures[len(neighbour.Outdoor.Measures)-1]; !last.Time.Before(oldest) {
+				temperature = append(temperature, last.Temperature)
+				humidity = append(humidity, float64(last.Humidity))
+			}
+		}
+	}
+	// compare our readings
+	if station.DashboardData.IsSet() && !station.DashboardData.Time.Before(oldest) {
+		comparison.Pressure = compareMeasure(station.DashboardData.Pressure, station.DashboardData.Time,
+			pressure, conf.PressureThreshold, conf)
+	}
+	for _, module := range station.Modules {
+		if module.DashboardDataOutdoor == nil {
+			continue
+		}
+		comparison.OutdoorModuleID = module.ID
+		dd := module.DashboardDataOutdoor
+		if dd.Time.Before(oldest) {
+			break
+		}
+		comparison.Temperature = compareMeasure(dd.Temperature, dd.Time, temperature, conf.TemperatureThreshold, conf)
+		comparison.Humidity = compareMeasure(float64(dd.Humidity), dd.Time, humidity, conf.HumidityThreshold, conf)
+		break
+	}
+	return
+}
+
+func (ac AnomalyConfig) withDefaults() AnomalyConfig {
+	if ac.Radius <= 0 {
+		ac.Radius = defaultAnomalyRadius
+	}
+	if ac.MinNeighbours <= 0 {
+		ac.MinNeighbours = defaultAnomalyMinNeighbours
+	}
+	if ac.TemperatureThreshold <= 0 {
+		ac.TemperatureThreshold = defaultAnomalyTemperatureThreshold
+	}
+	if ac.HumidityThreshold <= 0 {
+		ac.HumidityThreshold = defaultAnomalyHumidityThreshold
+	}
+	if ac.PressureThreshold <= 0 {
+		ac.PressureThreshold = defaultAnomalyPressureThreshold
+	}
+	if ac.Aggregate.Now.IsZero() {
+		ac.Aggregate.Now = time.Now()
+	}
+	if ac.Aggregate.MaxAge <= 0 {
+		ac.Aggregate.MaxAge = defaultAggregateMaxAge
+	}
+	if ac.Aggregate.MADThreshold <= 0 {
+		ac.Aggregate.MADThreshold = defaultAggregateMADThreshold
+	}
+	if ac.Aggregate.IQRFactor <= 0 {
+		ac.Aggregate.IQRFactor = defaultAggregateIQRFactor
+	}
+	if len(ac.Aggregate.Percentiles) == 0 {
+		ac.Aggregate.Percentiles = DefaultAggregatePercentiles
+	}
+	return ac
+}
+
+// compareMeasure returns nil if there is not enough neighbours values to build a baseline
+func compareMeasure(value float64, at time.Time, neighbours []float64, threshold float64, conf AnomalyConfig) *MeasureComparison {
+	kept := rejectOutliers(neighbours, conf.Aggregate)
+	if len(kept) < conf.MinNeighbours {
+		return nil
+	}
+	comparison := MeasureComparison{
+		Value:    value,
+		Time:     at,
+		Baseline: newAggregate(neighbours, conf.Aggregate),
+	}
+	// robust spread: 1.4826 makes the MAD consistent with the standard deviation of a normal distribution
+	deviations := make([]float64, len(kept))
+	for index, neighbour := range kept {
+		deviations[index] = math.Abs(neighbour - comparison.Baseline.Median)
+	}
+	comparison.Spread = 1.4826 * percentile(sortedCopy(deviations), 50)
+	comparison.Deviation = value - comparison.Baseline.Median
+	comparison.CalibrationOffset = -comparison.Deviation
+	comparison.Anomaly = math.Abs(comparison.Deviation) > threshold
+	// confidence grows with the number of neighbours (63% at MinNeighbours, 95% at 3 times MinNeighbours)
+	// and shrinks when the neighbours disagree with each other compared to the threshold
+	coverage := 1 - math.Exp(-float64(len(kept))/float64(conf.MinNeighbours))
+	agreement := threshold / (threshold + comparison.Spread)
+	comparison.Confidence = coverage * agreement
+	return &comparison
+}
@@ -0,0 +1,138 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/go-querystring/query"
+	"github.com/hekmon/go-netatmo"
+	"github.com/hekmon/go-netatmo/internal/measures"
+)
+
+// MeasureScale represents the timelapse between two measurements returned by GetMeasure()
+type MeasureScale string
+
+const (
+	// MeasureScaleMax represents every value stored (about 5 min timelapse)
+	MeasureScaleMax MeasureScale = "max"
+	// MeasureScale30Min represents a 30 minutes timelapse
+	MeasureScale30Min MeasureScale = "30min"
+	// MeasureScale1Hour represents a 1 hour timelapse
+	MeasureScale1Hour MeasureScale = "1hour"
+	// MeasureScale3Hours represents a 3 hours timelapse
+	MeasureScale3Hours MeasureScale = "3hours"
+	// MeasureScale1Day represents a 1 day timelapse
+	MeasureScale1Day MeasureScale = "1day"
+	// MeasureScale1Week represents a 1 week timelapse
+	MeasureScale1Week MeasureScale = "1week"
+	// MeasureScale1Month represents a 1 month timelapse
+	MeasureScale1Month MeasureScale = "1month"
+)
+
+// MeasureType represents a type of measure which can be requested with GetMeasure()
+type MeasureType string
+
+const (
+	// MeasureTypeTemperature represents the temperature (°C)
+	MeasureTypeTemperature MeasureType = "temperature"
+	// MeasureTypeHumidity represents the humidity (%)
+	MeasureTypeHumidity MeasureType = "humidity"
+	// MeasureTypePressure represents the pressure (mbar)
+	MeasureTypePressure MeasureType = "pressure"
+	// MeasureTypeCO2 represents the CO2 level (ppm)
+	MeasureTypeCO2 MeasureType = "co2"
+	// MeasureTypeNoise represents the noise level (dB)
+	MeasureTypeNoise MeasureType = "noise"
+	// MeasureTypeRain represents the rain (mm)
+	MeasureTypeRain MeasureType = "rain"
+	// MeasureTypeSumRain represents the rain sum over the scale (mm)
+	MeasureTypeSumRain MeasureType = "sum_rain"
+	// MeasureTypeWindStrength represents the wind strength (km/h)
+	MeasureTypeWindStrength MeasureType = "windstrength"
+	// MeasureTypeWindAngle represents the wind angle (°)
+	MeasureTypeWindAngle MeasureType = "windangle"
+	// MeasureTypeGustStrength represents the gust strength (km/h)
+	MeasureTypeGustStrength MeasureType = "guststrength"
+	// MeasureTypeGustAngle represents the gust angle (°)
+	MeasureTypeGustAngle MeasureType = "gustangle"
+	// MeasureTypeMinTemp represents the minimum temperature over the scale (°C)
+	MeasureTypeMinTemp MeasureType = "min_temp"
+	// MeasureTypeMaxTemp represents the maximum temperature over the scale (°C)
+	MeasureTypeMaxTemp MeasureType = "max_temp"
+	// MeasureTypeMinHum represents the minimum humidity over the scale (%)
+	MeasureTypeMinHum MeasureType = "min_hum"
+	// MeasureTypeMaxHum represents the maximum humidity over the scale (%)
+	MeasureTypeMaxHum MeasureType = "max_hum"
+	// MeasureTypeMinPressure represents the minimum pressure over the scale (mbar)
+	MeasureTypeMinPressure MeasureType = "min_pressure"
+	// MeasureTypeMaxPressure represents the maximum pressure over the scale (mbar)
+	MeasureTypeMaxPressure MeasureType = "max_pressure"
+	// MeasureTypeMinNoise represents the minimum noise level over the scale (dB)
+	MeasureTypeMinNoise MeasureType = "min_noise"
+	// MeasureTypeMaxNoise represents the maximum noise level over the scale (dB)
+	MeasureTypeMaxNoise MeasureType = "max_noise"
+)
+
+// GetMeasureParameters represents the parameters for GetMeasure()
+type GetMeasureParameters struct {
+	DeviceID  string        `url:"device_id"`                 // mac address of the station
+	ModuleID  string        `url:"module_id,omitempty"`       // mac address of the module you're interested in. If not specified, returns data of the station.
+	Scale     MeasureScale  `url:"scale"`                     // timelapse between two measurements
+	Types     []MeasureType `url:"type,comma"`                // types of measures wanted (must be available for the selected device/module)
+	DateBegin time.Time     `url:"date_begin,omitempty,unix"` // starting time
+	DateEnd   time.Time     `url:"date_end,omitempty,unix"`   // ending time
+	Limit     int           `url:"limit,omitempty"`           // maximum number of measurements (default and max are 1024)
+	Optimize  bool          `url:"optimize"`                  // determines the format of the answer, both are handled transparently
+	RealTime  bool          `url:"real_time,omitempty"`       // if scale different than max, timestamps are by default offset + scale/2. To get exact timestamps, use true.
+}
+
+// GetMeasure retrieves data from a device or module (Weather station and Thermostat only).
+// https://dev.netatmo.com/apidocumentation/weather#getmeasure
+func (wc *Client) GetMeasure(ctx context.Context, params GetMeasureParameters) (series MeasureSeries, headers http.Header, rs netatmo.RequestStats, err error) {
+	// verify
+	if params.DeviceID == "" {
+		err = errors.New("device ID can not be empty")
+		return
+	}
+	if params.Scale == "" {
+		err = errors.New("scale can not be empty")
+		return
+	}
+	if len(params.Types) == 0 {
+		err = errors.New("at least one measure type must be requested")
+		return
+	}
+	// prepare parameters
+	urlValues, err := query.Values(params)
+	if err != nil {
+		err = fmt.Errorf("can not convert params as URL values: %w", err)
+		return
+	}
+	// query
+	var body json.RawMessage
+	if headers, rs, err = wc.client.ExecuteNetatmoAPIRequest(ctx, "GET", "/getmeasure", urlValues, nil, &body); err != nil {
+		return
+	}
+	// parse
+	series.Types = params.Types
+	if series.Points, err = measures.Unmarshal(body, len(params.Types)); err != nil {
+		err = fmt.Errorf("failed to parse the measures: %w", err)
+	}
+	return
+}
+
+// MeasureSeries represents a time series of measures as returned by GetMeasure()
+type MeasureSeries struct {
+	Types  []MeasureType // measure types, in the same order as the values of each point
+	Points MeasurePoints // points sorted by time
+}
+
+// MeasurePoint represents the values of a series at a given time. A nil value means no data.
+type MeasurePoint = measures.Point
+
+// MeasurePoints is a collection of MeasurePoint
+type MeasurePoints = measures.Points
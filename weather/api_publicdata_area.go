@@ -0,0 +1,197 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAreaMaxTileSize    = 2 // degrees
+	defaultAreaDenseThreshold = 400
+	defaultAreaMaxDepth       = 6
+	defaultAreaConcurrency    = 4
+	defaultAreaMinInterval    = 200 * time.Millisecond // 50 requests per 10 seconds
+)
+
+// GetPublicDataAreaParameters represents the parameters for GetPublicDataArea().
+// Zero values of the tiling parameters are replaced by sane defaults.
+type GetPublicDataAreaParameters struct {
+	GetPublicDataParameters               // the whole area to query along with the data filters
+	MaxTileSize             float64       // maximum size (in degrees) of the initial tiles (default 2)
+	DenseThreshold          int           // number of stations returned for a tile beyond which it is subdivided (default 400)
+	MaxDepth                int           // maximum number of subdivisions of an initial tile (default 6)
+	Concurrency             int           // maximum number of concurrent requests (default 4)
+	MinInterval             time.Duration // minimum interval between two requests start (default 200ms)
+}
+
+// GetPublicDataArea retrieves publicly shared weather data within a large area. As Netatmo truncates the results of
+// large areas, the area is split into tiles which are recursively subdivided (quadtree) while they are dense.
+// Tiles are queried concurrently and stations are deduplicated by ID. Returned stations are sorted by ID.
+func (wc *Client) GetPublicDataArea(ctx context.Context, params GetPublicDataAreaParameters) (publicStations []PublicStationData, err error) {
+	// defaults
+	if params.MaxTileSize <= 0 {
+		params.MaxTileSize = defaultAreaMaxTileSize
+	}
+	if params.DenseThreshold <= 0 {
+		params.DenseThreshold = defaultAreaDenseThreshold
+	}
+	if params.MaxDepth <= 0 {
+		params.MaxDepth = defaultAreaMaxDepth
+	}
+	if params.Concurrency <= 0 {
+		params.Concurrency = defaultAreaConcurrency
+	}
+	if params.MinInterval <= 0 {
+		params.MinInterval = defaultAreaMinInterval
+	}
+	// verify
+	if err = validatePublicDataBox(params.GetPublicDataParameters); err != nil {
+		return
+	}
+	// prepare
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	aq := areaQuery{
+		wc:        wc,
+		params:    params,
+		semaphore: make(chan struct{}, params.Concurrency),
+		limiter:   time.NewTicker(params.MinInterval),
+		stations:  make(map[string]PublicStationData),
+		cancel:    cancel,
+	}
+	defer aq.limiter.Stop()
+	// query the initial tiles
+	for _, t := range newTiles(params.GetPublicDataParameters, params.MaxTileSize) {
+		aq.workers.Add(1)
+		go aq.query(ctx, t)
+	}
+	aq.workers.Wait()
+	if aq.err != nil {
+		err = aq.err
+		return
+	}
+	// tiles still waiting for their turn when ctx has been cancelled have not been queried
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	// build result
+	publicStations = make([]PublicStationData, 0, len(aq.stations))
+	for _, station := range aq.stations {
+		publicStations = append(publicStations, station)
+	}
+	sort.Slice(publicStations, func(i, j int) bool {
+		return publicStations[i].ID < publicStations[j].ID
+	})
+	return
+}
+
+type areaQuery struct {
+	wc        *Client
+	params    GetPublicDataAreaParameters
+	semaphore chan struct{}
+	limiter   *time.Ticker
+	workers   sync.WaitGroup
+	// results
+	access   sync.Mutex
+	stations map[string]PublicStationData
+	err      error
+	cancel   context.CancelFunc
+}
+
+func (aq *areaQuery) query(ctx context.Context, t tile) {
+	defer aq.workers.Done()
+	// wait for our turn
+	select {
+	case aq.semaphore <- struct{}{}:
+	case <-ctx.Done():
+		return
+	}
+	select {
+	case <-aq.limiter.C:
+	case <-ctx.Done():
+		<-aq.semaphore
+		return
+	}
+	// query the tile
+	params := aq.params.GetPublicDataParameters
+	params.NorthEastLatitude = t.neLat
+	params.NorthEastLongitude = t.neLon
+	params.SouthWestLatitude = t.swLat
+	params.SouthWestLongitude = t.swLon
+	stations, _, _, err := aq.wc.GetPublicData(ctx, params)
+	<-aq.semaphore
+	// handle result
+	aq.access.Lock()
+	defer aq.access.Unlock()
+	if err != nil {
+		if aq.err == nil {
+			aq.err = fmt.Errorf("failed to query tile %s: %w", t, err)
+			aq.cancel()
+		}
+		return
+	}
+	for _, station := range stations {
+		aq.stations[station.ID] = station
+	}
+	// subdivide dense tiles
+	if len(stations) >= aq.params.DenseThreshold && t.depth < aq.params.MaxDepth {
+		for _, child := range t.split() {
+			aq.workers.Add(1)
+			go aq.query(ctx, child)
+		}
+	}
+}
+
+// tile represents a lat/lon box of the quadtree
+type tile struct {
+	neLat, neLon float64
+	swLat, swLon float64
+	depth        int
+}
+
+func (t tile) String() string {
+	return fmt.Sprintf("[%f,%f %f,%f]", t.swLat, t.swLon, t.neLat, t.neLon)
+}
+
+func (t tile) split() [4]tile {
+	midLat := (t.neLat + t.swLat) / 2
+	midLon := (t.neLon + t.swLon) / 2
+	return [4]tile{
+		{neLat: t.neLat, neLon: midLon, swLat: midLat, swLon: t.swLon, depth: t.depth + 1}, // north west
+		{neLat: t.neLat, neLon: t.neLon, swLat: midLat, swLon: midLon, depth: t.depth + 1}, // north east
+		{neLat: midLat, neLon: midLon, swLat: t.swLat, swLon: t.swLon, depth: t.depth + 1}, // south west
+		{neLat: midLat, neLon: t.neLon, swLat: t.swLat, swLon: midLon, depth: t.depth + 1}, // south east
+	}
+}
+
+// newTiles splits a box into a grid of tiles whose sides are not larger than maxSize degrees
+func newTiles(params GetPublicDataParameters, maxSize float64) (tiles []tile) {
+	latSteps := int(math.Ceil((params.NorthEastLatitude - params.SouthWestLatitude) / maxSize))
+	lonSteps := int(math.Ceil((params.NorthEastLongitude - params.SouthWestLongitude) / maxSize))
+	latStep := (params.NorthEastLatitude - params.SouthWestLatitude) / float64(latSteps)
+	lonStep := (params.NorthEastLongitude - params.SouthWestLongitude) / float64(lonSteps)
+	tiles = make([]tile, 0, latSteps*lonSteps)
+	for latIndex := 0; latIndex < latSteps; latIndex++ {
+		for lonIndex := 0; lonIndex < lonSteps; lonIndex++ {
+			t := tile{
+				swLat: params.SouthWestLatitude + float64(latIndex)*latStep,
+				swLon: params.SouthWestLongitude + float64(lonIndex)*lonStep,
+				neLat: params.SouthWestLatitude + float64(latIndex+1)*latStep,
+				neLon: params.SouthWestLongitude + float64(lonIndex+1)*lonStep,
+			}
+			// avoid floating point drift on the outer edges
+			if latIndex == latSteps-1 {
+				t.neLat = params.NorthEastLatitude
+			}
+			if lonIndex == lonSteps-1 {
+				t.neLon = params.NorthEastLongitude
+			}
+			tiles = append(tiles, t)
+		}
+	}
+	return
+}
@@ -0,0 +1,144 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// PublicStationDistance is a public station along with its distance from the query origin
+type PublicStationDistance struct {
+	PublicStationData
+	Distance float64 `json:"distance"` // in kilometers
+}
+
+// UnmarshalJSON reads back the station payload along with its distance
+func (psd *PublicStationDistance) UnmarshalJSON(data []byte) (err error) {
+	if err = json.Unmarshal(data, &psd.PublicStationData); err != nil {
+		return
+	}
+	var tmp struct {
+		Distance float64 `json:"distance"`
+	}
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		err = fmt.Errorf("failed to unmarshal the distance: %w", err)
+		return
+	}
+	psd.Distance = tmp.Distance
+	return
+}
+
+// MarshalJSON adds the distance to the PublicStationData payload (the promoted PublicStationData one would drop it)
+func (psd PublicStationDistance) MarshalJSON() (data []byte, err error) {
+	if data, err = json.Marshal(psd.PublicStationData); err != nil {
+		return
+	}
+	var payload map[string]json.RawMessage
+	if err = json.Unmarshal(data, &payload); err != nil {
+		err = fmt.Errorf("failed to unmarshal the PublicStationData payload: %w", err)
+		return
+	}
+	if payload["distance"], err = json.Marshal(psd.Distance); err != nil {
+		err = fmt.Errorf("failed to marshal the distance: %w", err)
+		return
+	}
+	return json.Marshal(payload)
+}
+
+// GetPublicDataRadiusParameters represents the parameters for GetPublicDataRadius()
+type GetPublicDataRadiusParameters struct {
+	Center       GeoPoint     // center of the requested area
+	Radius       float64      // radius of the requested area, in kilometers
+	RequiredData RequiredData // see GetPublicDataParameters
+	Filter       bool         // see GetPublicDataParameters
+}
+
+// GetPublicDataRadius retrieves the public stations within radius kilometers of a center point.
+// Stations are sorted by distance. Areas crossing the antimeridian are not supported.
+func (wc *Client) GetPublicDataRadius(ctx context.Context, params GetPublicDataRadiusParameters) (publicStations []PublicStationDistance, err error) {
+	if params.Radius <= 0 {
+		err = errors.New("radius must be positive")
+		return
+	}
+	ne, sw := params.Center.BoundingBox(params.Radius)
+	stations, _, _, err := wc.GetPublicData(ctx, GetPublicDataParameters{
+		NorthEastLatitude:  ne.Latitude,
+		NorthEastLongitude: ne.Longitude,
+		SouthWestLatitude:  sw.Latitude,
+		SouthWestLongitude: sw.Longitude,
+		RequiredData:       params.RequiredData,
+		Filter:             params.Filter,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to get public data for the covering box: %w", err)
+		return
+	}
+	publicStations = filterByDistance(stations, params.Center, func(point GeoPoint, distance float64) bool {
+		return distance <= params.Radius
+	})
+	return
+}
+
+// GetPublicDataPolygonParameters represents the parameters for GetPublicDataPolygon()
+type GetPublicDataPolygonParameters struct {
+	Polygon      GeoPolygon   // requested area, see ParseGeoJSONPolygon()
+	Origin       *GeoPoint    // point used to compute the stations distance, polygon centroid if nil
+	RequiredData RequiredData // see GetPublicDataParameters
+	Filter       bool         // see GetPublicDataParameters
+}
+
+// GetPublicDataPolygon retrieves the public stations located within a polygon.
+// Stations are sorted by distance from the origin. Polygons crossing the antimeridian are not supported.
+func (wc *Client) GetPublicDataPolygon(ctx context.Context, params GetPublicDataPolygonParameters) (publicStations []PublicStationDistance, err error) {
+	if len(params.Polygon) == 0 || len(params.Polygon[0]) < 3 {
+		err = errors.New("polygon must have an exterior ring of at least 3 positions")
+		return
+	}
+	ne, sw := params.Polygon.BoundingBox()
+	stations, _, _, err := wc.GetPublicData(ctx, GetPublicDataParameters{
+		NorthEastLatitude:  ne.Latitude,
+		NorthEastLongitude: ne.Longitude,
+		SouthWestLatitude:  sw.Latitude,
+		SouthWestLongitude: sw.Longitude,
+		RequiredData:       params.RequiredData,
+		Filter:             params.Filter,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to get public data for the covering box: %w", err)
+		return
+	}
+	origin := params.Polygon.Centroid()
+	if params.Origin != nil {
+		origin = *params.Origin
+	}
+	publicStations = filterByDistance(stations, origin, func(point GeoPoint, distance float64) bool {
+		return params.Polygon.Contains(point)
+	})
+	return
+}
+
+// filterByDistance keeps the stations accepted by keep and sorts them by distance from origin
+func filterByDistance(stations []PublicStationData, origin GeoPoint,
+	keep func(point GeoPoint, distance float64) bool) (filtered []PublicStationDistance) {
+	filtered = make([]PublicStationDistance, 0, len(stations))
+	for _, station := range stations {
+		point, ok := station.Place.Point()
+		if !ok {
+			continue
+		}
+		distance := origin.Distance(point)
+		if !keep(point, distance) {
+			continue
+		}
+		filtered = append(filtered, PublicStationDistance{
+			PublicStationData: station,
+			Distance:          distance,
+		})
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		return filtered[i].Distance < filtered[j].Distance
+	})
+	return
+}
@@ -0,0 +1,215 @@
+package weather
+
+import (
+	"sort"
+	"time"
+)
+
+// ExportRecord is a flat representation of the measures of a station or a module at a given time.
+// It is the common format consumed by the InfluxWriter and the CSVWriter.
+type ExportRecord struct {
+	Time       time.Time
+	StationID  string
+	ModuleID   string // same as StationID for the station itself
+	HomeName   string
+	ModuleName string
+	ModuleType ModuleType
+	Place      *Place             // can be nil (getmeasure series without base place)
+	Fields     map[string]float64 // measure name => value, only contains available values
+}
+
+// FieldNames returns the fields names of the record, sorted
+func (er ExportRecord) FieldNames() (names []string) {
+	names = make([]string, 0, len(er.Fields))
+	for name := range er.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// StationDataRecords flattens the dashboards of every reachable station and module of data.
+// Fields are generated for each ModuleDataType the station or module declares.
+func StationDataRecords(data StationDataBody) (records []ExportRecord) {
+	for stationIndex := range data.Devices {
+		station := &data.Devices[stationIndex]
+		if station.DashboardData.IsSet() {
+			dd := station.DashboardData
+			record := ExportRecord{
+				Time:       dd.Time,
+				StationID:  station.ID,
+				ModuleID:   station.ID,
+				HomeName:   station.HomeName,
+				ModuleName: station.ModuleName,
+				ModuleType: station.Type,
+				Place:      &station.Place,
+				Fields:     make(map[string]float64),
+			}
+			for _, dataType := range station.DataType {
+				switch dataType {
+				case ModuleDataTypeTemperature:
+					record.Fields["temperature"] = dd.Temperature
+					record.Fields["min_temp"] = dd.TempMin
+					record.Fields["max_temp"] = dd.TempMax
+				case ModuleDataTypeCO2:
+					record.Fields["co2"] = float64(dd.CO2)
+				case ModuleDataTypeHumidity:
+					record.Fields["humidity"] = float64(dd.Humidity)
+				case ModuleDataTypeNoise:
+					record.Fields["noise"] = float64(dd.Noise)
+				case ModuleDataTypePressure:
+					record.Fields["pressure"] = dd.Pressure
+					record.Fields["absolute_pressure"] = dd.AbsolutePressure
+				}
+			}
+			records = append(records, record)
+		}
+		for _, module := range station.Modules {
+			if !module.Reachable {
+				continue
+			}
+			record := ExportRecord{
+				StationID:  station.ID,
+				ModuleID:   module.ID,
+				HomeName:   station.HomeName,
+				ModuleName: module.ModuleName,
+				ModuleType: module.Type,
+				Place:      &station.Place,
+				Fields:     make(map[string]float64),
+			}
+			for _, dataType := range module.DataType {
+				addModuleFields(&record, module, dataType)
+			}
+			if len(record.Fields) > 0 {
+				records = append(records, record)
+			}
+		}
+	}
+	return
+}
+
+func addModuleFields(record *ExportRecord, module Module, dataType ModuleDataType) {
+	switch {
+	case module.DashboardDataOutdoor != nil:
+		dd := module.DashboardDataOutdoor
+		record.Time = dd.Time
+		switch dataType {
+		case ModuleDataTypeTemperature:
+			record.Fields["temperature"] = dd.Temperature
+			record.Fields["min_temp"] = dd.MinTemp
+			record.Fields["max_temp"] = dd.MaxTemp
+		case ModuleDataTypeHumidity:
+			record.Fields["humidity"] = float64(dd.Humidity)
+		}
+	case module.DashboardDataIndoor != nil:
+		dd := module.DashboardDataIndoor
+		record.Time = dd.Time
+		switch dataType {
+		case ModuleDataTypeTemperature:
+			record.Fields["temperature"] = dd.Temperature
+			record.Fields["min_temp"] = dd.MinTemp
+			record.Fields["max_temp"] = dd.MaxTemp
+		case ModuleDataTypeHumidity:
+			record.Fields["humidity"] = float64(dd.Humidity)
+		case ModuleDataTypeCO2:
+			record.Fields["co2"] = float64(dd.CO2)
+		}
+	case module.DashboardDataWind != nil:
+		dd := module.DashboardDataWind
+		record.Time = dd.Time
+		if dataType == ModuleDataTypeWind {
+			record.Fields["wind_strength"] = float64(dd.WindStrength)
+			record.Fields["wind_angle"] = float64(dd.WindAngle)
+			record.Fields["gust_strength"] = float64(dd.GustStrength)
+			record.Fields["gust_angle"] = float64(dd.GustAngle)
+			record.Fields["max_wind_strength"] = float64(dd.MaxWindStr)
+			record.Fields["max_wind_angle"] = float64(dd.MaxWindAngle)
+		}
+	case module.DashboardDataRain != nil:
+		dd := module.DashboardDataRain
+		record.Time = dd.Time
+		if dataType == ModuleDataTypeRain {
+			record.Fields["rain"] = dd.Rain
+			record.Fields["sum_rain_1"] = dd.SumRain1
+			record.Fields["sum_rain_24"] = dd.SumRain24
+		}
+	}
+}
+
+// PublicDataRecords flattens the measures of public stations: one record is generated for each module and timestamp
+func PublicDataRecords(stations []PublicStationData) (records []ExportRecord) {
+	for stationIndex := range stations {
+		station := &stations[stationIndex]
+		base := ExportRecord{
+			StationID:  station.ID,
+			ModuleID:   station.ID,
+			ModuleType: ModuleTypeStation,
+			Place:      &station.Place,
+		}
+		for _, pressure := range station.Pressure {
+			record := base
+			record.Time = pressure.Time
+			record.Fields = map[string]float64{
+				"pressure": pressure.Pressure,
+			}
+			records = append(records, record)
+		}
+		if station.Outdoor != nil {
+			for _, outdoor := range station.Outdoor.Measures {
+				record := base
+				record.ModuleID = station.Outdoor.ID
+				record.ModuleType = ModuleTypeOutdoor
+				record.Time = outdoor.Time
+				record.Fields = map[string]float64{
+					"temperature": outdoor.Temperature,
+					"humidity":    float64(outdoor.Humidity),
+				}
+				records = append(records, record)
+			}
+		}
+		if station.Wind != nil {
+			record := base
+			record.ModuleID = station.Wind.ID
+			record.ModuleType = ModuleTypeAnemometer
+			record.Time = station.Wind.Measures.Time
+			record.Fields = map[string]float64{
+				"wind_strength": float64(station.Wind.Measures.WindStrength),
+				"wind_angle":    float64(station.Wind.Measures.WindAngle),
+				"gust_strength": float64(station.Wind.Measures.GustStrength),
+				"gust_angle":    float64(station.Wind.Measures.GustAngle),
+			}
+			records = append(records, record)
+		}
+		if station.Rain != nil {
+			record := base
+			record.ModuleID = station.Rain.ID
+			record.ModuleType = ModuleTypeRainGauge
+			record.Time = station.Rain.Measures.Time
+			record.Fields = map[string]float64{
+				"rain":        station.Rain.Measures.RainLive,
+				"sum_rain_1":  station.Rain.Measures.Rain60min,
+				"sum_rain_24": station.Rain.Measures.Rain24h,
+			}
+			records = append(records, record)
+		}
+	}
+	return
+}
+
+// MeasureSeriesRecords flattens a getmeasure series: one record is generated per point, the identification
+// fields (IDs, names, type, place) are copied from base. Fields are named after the measure types.
+func MeasureSeriesRecords(series MeasureSeries, base ExportRecord) (records []ExportRecord) {
+	records = make([]ExportRecord, 0, len(series.Points))
+	for _, point := range series.Points {
+		record := base
+		record.Time = point.Time
+		record.Fields = make(map[string]float64, len(series.Types))
+		for index, value := range point.Values {
+			if value != nil && index < len(series.Types) {
+				record.Fields[string(series.Types[index])] = *value
+			}
+		}
+		records = append(records, record)
+	}
+	return
+}
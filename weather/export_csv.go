@@ -0,0 +1,148 @@
+package weather
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// CSV columns not being a measure field. Any other column name is looked up in the records fields.
+const (
+	CSVColumnTime       = "time"
+	CSVColumnStationID  = "station_id"
+	CSVColumnModuleID   = "module_id"
+	CSVColumnHomeName   = "home_name"
+	CSVColumnModuleName = "module_name"
+	CSVColumnModuleType = "module_type"
+	CSVColumnCountry    = "country"
+	CSVColumnTimezone   = "timezone"
+	CSVColumnLatitude   = "latitude"
+	CSVColumnLongitude  = "longitude"
+	CSVColumnAltitude   = "altitude"
+)
+
+// DefaultCSVColumns are the columns used by the CSVWriter if none are set
+var DefaultCSVColumns = []string{
+	CSVColumnTime, CSVColumnStationID, CSVColumnModuleID, CSVColumnHomeName, CSVColumnModuleName, CSVColumnModuleType,
+	"temperature", "humidity", "co2", "noise", "pressure", "absolute_pressure",
+	"wind_strength", "wind_angle", "gust_strength", "gust_angle", "rain", "sum_rain_1", "sum_rain_24",
+}
+
+// CSVWriter streams records to w as RFC 4180 CSV. The header is written before the first record.
+type CSVWriter struct {
+	Columns    []string       // columns to write, DefaultCSVColumns if empty
+	Location   *time.Location // time zone used to format the time column, UTC if nil
+	TimeLayout string         // layout used to format the time column, time.RFC3339 if empty
+	w          *csv.Writer
+	header     bool
+}
+
+// NewCSVWriter returns a CSVWriter writing to w
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	cw := &CSVWriter{
+		w: csv.NewWriter(w),
+	}
+	cw.w.UseCRLF = true
+	return cw
+}
+
+// WriteStationData writes the stations and modules dashboards of data
+func (cw *CSVWriter) WriteStationData(data StationDataBody) error {
+	return cw.WriteRecords(StationDataRecords(data))
+}
+
+// WritePublicData writes the measures of the public stations
+func (cw *CSVWriter) WritePublicData(stations []PublicStationData) error {
+	return cw.WriteRecords(PublicDataRecords(stations))
+}
+
+// WriteMeasureSeries writes a getmeasure series, identification columns are taken from base (see MeasureSeriesRecords())
+func (cw *CSVWriter) WriteMeasureSeries(series MeasureSeries, base ExportRecord) error {
+	return cw.WriteRecords(MeasureSeriesRecords(series, base))
+}
+
+// WriteRecords writes one row per record and flushes the underlying writer
+func (cw *CSVWriter) WriteRecords(records []ExportRecord) (err error) {
+	if len(cw.Columns) == 0 {
+		cw.Columns = DefaultCSVColumns
+	}
+	if !cw.header {
+		if err = cw.w.Write(cw.Columns); err != nil {
+			return fmt.Errorf("failed to write CSV header: %w", err)
+		}
+		cw.header = true
+	}
+	row := make([]string, len(cw.Columns))
+	for _, record := range records {
+		for index, column := range cw.Columns {
+			row[index] = cw.cell(record, column)
+		}
+		if err = cw.w.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	cw.w.Flush()
+	if err = cw.w.Error(); err != nil {
+		err = fmt.Errorf("failed to flush CSV: %w", err)
+	}
+	return
+}
+
+func (cw *CSVWriter) cell(record ExportRecord, column string) string {
+	switch column {
+	case CSVColumnTime:
+		if record.Time.IsZero() {
+			return ""
+		}
+		location := cw.Location
+		if location == nil {
+			location = time.UTC
+		}
+		layout := cw.TimeLayout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return record.Time.In(location).Format(layout)
+	case CSVColumnStationID:
+		return record.StationID
+	case CSVColumnModuleID:
+		return record.ModuleID
+	case CSVColumnHomeName:
+		return record.HomeName
+	case CSVColumnModuleName:
+		return record.ModuleName
+	case CSVColumnModuleType:
+		return string(record.ModuleType)
+	case CSVColumnCountry:
+		if record.Place == nil {
+			return ""
+		}
+		return record.Place.Country
+	case CSVColumnTimezone:
+		if record.Place == nil || record.Place.Timezone == nil {
+			return ""
+		}
+		return record.Place.Timezone.String()
+	case CSVColumnLatitude, CSVColumnLongitude:
+		// Place.Location is [longitude, latitude]
+		if record.Place == nil || len(record.Place.Location) != 2 {
+			return ""
+		}
+		if column == CSVColumnLongitude {
+			return strconv.FormatFloat(record.Place.Location[0], 'f', -1, 64)
+		}
+		return strconv.FormatFloat(record.Place.Location[1], 'f', -1, 64)
+	case CSVColumnAltitude:
+		if record.Place == nil {
+			return ""
+		}
+		return strconv.FormatFloat(record.Place.Altitude, 'f', -1, 64)
+	default:
+		if value, found := record.Fields[column]; found {
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		}
+		return ""
+	}
+}
@@ -0,0 +1,115 @@
+package weather
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultInfluxMeasurement is the measurement name used by the InfluxWriter if none is set
+	DefaultInfluxMeasurement = "netatmo"
+)
+
+// line protocol can not escape line breaks: they are replaced by (escaped) spaces
+var (
+	influxMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "\r\n", `\ `, "\n", `\ `, "\r", `\ `)
+	influxTagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\r\n", `\ `, "\n", `\ `, "\r", `\ `)
+)
+
+// InfluxWriter streams records to w using the InfluxDB line protocol.
+// https://docs.influxdata.com/influxdb/v2.0/reference/syntax/line-protocol/
+type InfluxWriter struct {
+	Measurement string // measurement name, DefaultInfluxMeasurement if empty
+	w           *bufio.Writer
+}
+
+// NewInfluxWriter returns an InfluxWriter writing to w
+func NewInfluxWriter(w io.Writer) *InfluxWriter {
+	return &InfluxWriter{
+		w: bufio.NewWriter(w),
+	}
+}
+
+// WriteStationData writes the stations and modules dashboards of data
+func (iw *InfluxWriter) WriteStationData(data StationDataBody) error {
+	return iw.WriteRecords(StationDataRecords(data))
+}
+
+// WritePublicData writes the measures of the public stations
+func (iw *InfluxWriter) WritePublicData(stations []PublicStationData) error {
+	return iw.WriteRecords(PublicDataRecords(stations))
+}
+
+// WriteMeasureSeries writes a getmeasure series, tags are generated from base (see MeasureSeriesRecords())
+func (iw *InfluxWriter) WriteMeasureSeries(series MeasureSeries, base ExportRecord) error {
+	return iw.WriteRecords(MeasureSeriesRecords(series, base))
+}
+
+// WriteRecords writes one line per record and flushes the underlying writer. Records without fields are skipped.
+func (iw *InfluxWriter) WriteRecords(records []ExportRecord) (err error) {
+	for _, record := range records {
+		if len(record.Fields) == 0 {
+			continue
+		}
+		if _, err = iw.w.WriteString(iw.line(record)); err != nil {
+			return fmt.Errorf("failed to write line protocol: %w", err)
+		}
+	}
+	if err = iw.w.Flush(); err != nil {
+		err = fmt.Errorf("failed to flush line protocol: %w", err)
+	}
+	return
+}
+
+func (iw *InfluxWriter) line(record ExportRecord) string {
+	var buff strings.Builder
+	// measurement
+	measurement := iw.Measurement
+	if measurement == "" {
+		measurement = DefaultInfluxMeasurement
+	}
+	buff.WriteString(influxMeasurementEscaper.Replace(measurement))
+	// tags (sorted by key as recommended)
+	if record.Place != nil {
+		writeInfluxTag(&buff, "country", record.Place.Country)
+	}
+	writeInfluxTag(&buff, "home_name", record.HomeName)
+	writeInfluxTag(&buff, "module_id", record.ModuleID)
+	writeInfluxTag(&buff, "module_name", record.ModuleName)
+	writeInfluxTag(&buff, "module_type", string(record.ModuleType))
+	writeInfluxTag(&buff, "station_id", record.StationID)
+	if record.Place != nil && record.Place.Timezone != nil {
+		writeInfluxTag(&buff, "timezone", record.Place.Timezone.String())
+	}
+	// fields
+	for index, name := range record.FieldNames() {
+		if index == 0 {
+			buff.WriteByte(' ')
+		} else {
+			buff.WriteByte(',')
+		}
+		buff.WriteString(influxTagEscaper.Replace(name))
+		buff.WriteByte('=')
+		buff.WriteString(strconv.FormatFloat(record.Fields[name], 'f', -1, 64))
+	}
+	// timestamp
+	if !record.Time.IsZero() {
+		buff.WriteByte(' ')
+		buff.WriteString(strconv.FormatInt(record.Time.UnixNano(), 10))
+	}
+	buff.WriteByte('\n')
+	return buff.String()
+}
+
+func writeInfluxTag(buff *strings.Builder, key, value string) {
+	if value == "" {
+		return
+	}
+	buff.WriteByte(',')
+	buff.WriteString(key)
+	buff.WriteByte('=')
+	buff.WriteString(influxTagEscaper.Replace(value))
+}
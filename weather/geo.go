@@ -0,0 +1,173 @@
+package weather
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	// EarthRadius is the mean earth radius in kilometers, used for distances computations
+	EarthRadius = 6371.0088
+)
+
+// GeoPoint represents a WGS84 position
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Point returns the position of the place. ok is false if the place does not have a valid location.
+func (p Place) Point() (point GeoPoint, ok bool) {
+	if len(p.Location) != 2 {
+		return
+	}
+	// Netatmo locations are [longitude, latitude]
+	return GeoPoint{
+		Latitude:  p.Location[1],
+		Longitude: p.Location[0],
+	}, true
+}
+
+// Distance returns the great-circle distance in kilometers between gp and other (haversine formula)
+func (gp GeoPoint) Distance(other GeoPoint) float64 {
+	lat1 := gp.Latitude * math.Pi / 180
+	lat2 := other.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (other.Longitude - gp.Longitude) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// BoundingBox returns the lat/lon box covering the circle of radius kilometers around gp,
+// clamped to the bounds accepted by GetPublicData().
+func (gp GeoPoint) BoundingBox(radius float64) (ne, sw GeoPoint) {
+	dLat := radius / EarthRadius * 180 / math.Pi
+	ne.Latitude = math.Min(gp.Latitude+dLat, maxLat)
+	sw.Latitude = math.Max(gp.Latitude-dLat, minLat)
+	// longitude delta grows with latitude, use the widest latitude of the box
+	widest := math.Max(math.Abs(ne.Latitude), math.Abs(sw.Latitude)) * math.Pi / 180
+	if cos := math.Cos(widest); cos > 0 && radius < EarthRadius*math.Pi*cos {
+		dLon := radius / (EarthRadius * cos) * 180 / math.Pi
+		ne.Longitude = math.Min(gp.Longitude+dLon, maxLon)
+		sw.Longitude = math.Max(gp.Longitude-dLon, minLon)
+	} else {
+		ne.Longitude = maxLon
+		sw.Longitude = minLon
+	}
+	return
+}
+
+// GeoPolygon represents a polygon: the first ring is the exterior, the others are holes.
+// Rings are lists of positions, closing the ring (repeating the first position) is optional.
+type GeoPolygon [][]GeoPoint
+
+// ParseGeoJSONPolygon parses a GeoJSON Polygon geometry or a Feature whose geometry is a Polygon.
+// https://datatracker.ietf.org/doc/html/rfc7946#section-3.1.6
+func ParseGeoJSONPolygon(data []byte) (polygon GeoPolygon, err error) {
+	var geometry struct {
+		Type        string          `json:"type"`
+		Coordinates [][][]float64   `json:"coordinates"`
+		Geometry    json.RawMessage `json:"geometry"`
+	}
+	if err = json.Unmarshal(data, &geometry); err != nil {
+		err = fmt.Errorf("failed to unmarshal GeoJSON: %w", err)
+		return
+	}
+	switch geometry.Type {
+	case "Feature":
+		if len(geometry.Geometry) == 0 {
+			err = errors.New("GeoJSON feature has no geometry")
+			return
+		}
+		return ParseGeoJSONPolygon(geometry.Geometry)
+	case "Polygon":
+		// continue
+	default:
+		err = fmt.Errorf("unsupported GeoJSON type '%s', expecting 'Polygon' or 'Feature'", geometry.Type)
+		return
+	}
+	if len(geometry.Coordinates) == 0 {
+		err = errors.New("GeoJSON polygon has no ring")
+		return
+	}
+	polygon = make(GeoPolygon, len(geometry.Coordinates))
+	for ringIndex, ring := range geometry.Coordinates {
+		if len(ring) < 3 {
+			err = fmt.Errorf("GeoJSON polygon ring %d has less than 3 positions", ringIndex)
+			return
+		}
+		polygon[ringIndex] = make([]GeoPoint, len(ring))
+		for positionIndex, position := range ring {
+			if len(position) < 2 {
+				err = fmt.Errorf("GeoJSON polygon ring %d position %d is invalid: %v", ringIndex, positionIndex, position)
+				return
+			}
+			polygon[ringIndex][positionIndex] = GeoPoint{
+				Latitude:  position[1],
+				Longitude: position[0],
+			}
+		}
+	}
+	return
+}
+
+// BoundingBox returns the lat/lon box covering the exterior ring of the polygon
+func (gp GeoPolygon) BoundingBox() (ne, sw GeoPoint) {
+	if len(gp) == 0 || len(gp[0]) == 0 {
+		return
+	}
+	ne, sw = gp[0][0], gp[0][0]
+	for _, point := range gp[0][1:] {
+		ne.Latitude = math.Max(ne.Latitude, point.Latitude)
+		ne.Longitude = math.Max(ne.Longitude, point.Longitude)
+		sw.Latitude = math.Min(sw.Latitude, point.Latitude)
+		sw.Longitude = math.Min(sw.Longitude, point.Longitude)
+	}
+	return
+}
+
+// Centroid returns the average position of the exterior ring vertices
+func (gp GeoPolygon) Centroid() (centroid GeoPoint) {
+	if len(gp) == 0 || len(gp[0]) == 0 {
+		return
+	}
+	ring := gp[0]
+	// do not count the closing position twice
+	if len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+		ring = ring[:len(ring)-1]
+	}
+	for _, point := range ring {
+		centroid.Latitude += point.Latitude
+		centroid.Longitude += point.Longitude
+	}
+	centroid.Latitude /= float64(len(ring))
+	centroid.Longitude /= float64(len(ring))
+	return
+}
+
+// Contains returns true if point is within the exterior ring and outside of all the holes
+func (gp GeoPolygon) Contains(point GeoPoint) bool {
+	if len(gp) == 0 || !ringContains(gp[0], point) {
+		return false
+	}
+	for _, hole := range gp[1:] {
+		if ringContains(hole, point) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringContains uses the ray casting algorithm on the lat/lon plane
+func ringContains(ring []GeoPoint, point GeoPoint) (inside bool) {
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Latitude > point.Latitude) != (b.Latitude > point.Latitude) &&
+			point.Longitude < (b.Longitude-a.Longitude)*(point.Latitude-a.Latitude)/(b.Latitude-a.Latitude)+a.Longitude {
+			inside = !inside
+		}
+	}
+	return
+}
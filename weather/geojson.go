@@ -0,0 +1,174 @@
+package weather
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+/*
+	https://datatracker.ietf.org/doc/html/rfc7946
+*/
+
+// GeoJSONFeatureCollection represents a GeoJSON FeatureCollection
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"` // always "FeatureCollection"
+	Features []GeoJSONFeature `json:"features"`
+}
+
+// GeoJSONFeature represents a GeoJSON Feature with a point geometry
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"` // always "Feature"
+	ID         string                 `json:"id"`
+	Geometry   GeoJSONPoint           `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GeoJSONPoint represents a GeoJSON Point geometry
+type GeoJSONPoint struct {
+	Type        string    `json:"type"`        // always "Point"
+	Coordinates []float64 `json:"coordinates"` // longitude, latitude, altitude
+}
+
+// WriteTo writes the indented JSON representation of the collection to w (implements https://pkg.go.dev/io#WriterTo)
+func (gjfc GeoJSONFeatureCollection) WriteTo(w io.Writer) (n int64, err error) {
+	data, err := json.MarshalIndent(gjfc, "", "\t")
+	if err != nil {
+		err = fmt.Errorf("failed to marshal the GeoJSON feature collection: %w", err)
+		return
+	}
+	written, err := w.Write(append(data, '\n'))
+	n = int64(written)
+	return
+}
+
+// PublicDataGeoJSON returns the public stations as a GeoJSON FeatureCollection. Stations without location are skipped.
+func PublicDataGeoJSON(stations []PublicStationData) (collection GeoJSONFeatureCollection) {
+	collection = GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]GeoJSONFeature, 0, len(stations)),
+	}
+	for _, station := range stations {
+		if feature, ok := station.GeoJSONFeature(); ok {
+			collection.Features = append(collection.Features, feature)
+		}
+	}
+	return
+}
+
+// StationDataGeoJSON returns the user stations as a GeoJSON FeatureCollection. Stations without location are skipped.
+func StationDataGeoJSON(data StationDataBody) (collection GeoJSONFeatureCollection) {
+	collection = GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]GeoJSONFeature, 0, len(data.Devices)),
+	}
+	for _, station := range data.Devices {
+		if feature, ok := station.GeoJSONFeature(); ok {
+			collection.Features = append(collection.Features, feature)
+		}
+	}
+	return
+}
+
+// GeoJSONFeature returns the public station as a GeoJSON Feature holding its latest measures.
+// ok is false if the station does not have a location.
+func (psd PublicStationData) GeoJSONFeature() (feature GeoJSONFeature, ok bool) {
+	if feature, ok = newGeoJSONFeature(psd.ID, psd.Place); !ok {
+		return
+	}
+	feature.Properties["mark"] = psd.Mark
+	if len(psd.Pressure) > 0 {
+		last := psd.Pressure[len(psd.Pressure)-1]
+		addGeoJSONPressure(feature.Properties, last.Pressure, last.Time)
+	}
+	if psd.Outdoor != nil && len(psd.Outdoor.Measures) > 0 {
+		last := psd.Outdoor.Measures[len(psd.Outdoor.Measures)-1]
+		addGeoJSONOutdoor(feature.Properties, last.Temperature, float64(last.Humidity), last.Time)
+	}
+	if psd.Wind != nil {
+		wm := psd.Wind.Measures
+		addGeoJSONWind(feature.Properties, wm.WindStrength, wm.WindAngle, wm.GustStrength, wm.GustAngle, wm.Time)
+	}
+	if psd.Rain != nil {
+		rm := psd.Rain.Measures
+		addGeoJSONRain(feature.Properties, rm.RainLive, rm.Rain60min, rm.Rain24h, rm.Time)
+	}
+	return
+}
+
+// GeoJSONFeature returns the station as a GeoJSON Feature holding its latest measures
+// and the ones of its reachable outdoor, wind and rain modules. ok is false if the station does not have a location.
+func (sdbd StationDataBodyDevices) GeoJSONFeature() (feature GeoJSONFeature, ok bool) {
+	if feature, ok = newGeoJSONFeature(sdbd.ID, sdbd.Place); !ok {
+		return
+	}
+	feature.Properties["home_name"] = sdbd.HomeName
+	feature.Properties["module_name"] = sdbd.ModuleName
+	feature.Properties["reachable"] = sdbd.Reachable
+	if sdbd.DashboardData.IsSet() {
+		addGeoJSONPressure(feature.Properties, sdbd.DashboardData.Pressure, sdbd.DashboardData.Time)
+	}
+	for _, module := range sdbd.Modules {
+		switch {
+		case module.DashboardDataOutdoor != nil:
+			dd := module.DashboardDataOutdoor
+			addGeoJSONOutdoor(feature.Properties, dd.Temperature, float64(dd.Humidity), dd.Time)
+		case module.DashboardDataWind != nil:
+			dd := module.DashboardDataWind
+			addGeoJSONWind(feature.Properties, dd.WindStrength, dd.WindAngle, dd.GustStrength, dd.GustAngle, dd.Time)
+		case module.DashboardDataRain != nil:
+			dd := module.DashboardDataRain
+			addGeoJSONRain(feature.Properties, dd.Rain, dd.SumRain1, dd.SumRain24, dd.Time)
+		}
+	}
+	return
+}
+
+func newGeoJSONFeature(id string, place Place) (feature GeoJSONFeature, ok bool) {
+	point, ok := place.Point()
+	if !ok {
+		return
+	}
+	feature = GeoJSONFeature{
+		Type: "Feature",
+		ID:   id,
+		Geometry: GeoJSONPoint{
+			Type:        "Point",
+			Coordinates: []float64{point.Longitude, point.Latitude, place.Altitude},
+		},
+		Properties: map[string]interface{}{
+			"country": place.Country,
+		},
+	}
+	if place.Timezone != nil {
+		feature.Properties["timezone"] = place.Timezone.String()
+	}
+	return
+}
+
+func addGeoJSONPressure(properties map[string]interface{}, pressure float64, at time.Time) {
+	properties["pressure"] = pressure
+	properties["pressure_time"] = at.UTC().Format(time.RFC3339)
+}
+
+func addGeoJSONOutdoor(properties map[string]interface{}, temperature, humidity float64, at time.Time) {
+	properties["temperature"] = temperature
+	properties["humidity"] = humidity
+	properties["outdoor_time"] = at.UTC().Format(time.RFC3339)
+}
+
+func addGeoJSONWind(properties map[string]interface{}, windStrength, windAngle, gustStrength, gustAngle int, at time.Time) {
+	properties["wind_strength"] = windStrength
+	properties["wind_angle"] = windAngle
+	properties["gust_strength"] = gustStrength
+	properties["gust_angle"] = gustAngle
+	properties["wind_time"] = at.UTC().Format(time.RFC3339)
+}
+
+func addGeoJSONRain(properties map[string]interface{}, live, sum1, sum24 float64, at time.Time) {
+	properties["rain_live"] = live
+	properties["rain_60min"] = sum1
+	properties["rain_24h"] = sum24
+	properties["rain_time"] = at.UTC().Format(time.RFC3339)
+}
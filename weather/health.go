@@ -0,0 +1,343 @@
+package weather
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHealthStaleWarning  = time.Hour
+	defaultHealthStaleCritical = StationOfflineThreshold
+)
+
+// HealthSeverity represents the severity of a health check. Values match the Nagios/Icinga plugins return codes.
+type HealthSeverity int
+
+const (
+	// HealthOK represents a passing check
+	HealthOK HealthSeverity = 0
+	// HealthWarning represents a check needing attention
+	HealthWarning HealthSeverity = 1
+	// HealthCritical represents a failing check
+	HealthCritical HealthSeverity = 2
+	// HealthUnknown represents a check which could not be evaluated
+	HealthUnknown HealthSeverity = 3
+)
+
+// String implements the https://golang.org/pkg/fmt/#Stringer interface
+func (hs HealthSeverity) String() string {
+	switch hs {
+	case HealthOK:
+		return "OK"
+	case HealthWarning:
+		return "WARNING"
+	case HealthCritical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// GoString implements the https://golang.org/pkg/fmt/#GoStringer interface
+func (hs HealthSeverity) GoString() string {
+	return fmt.Sprintf("%s (%d)", hs.String(), hs)
+}
+
+// MarshalText allows the severity to be encoded as its string representation
+func (hs HealthSeverity) MarshalText() (text []byte, err error) {
+	return []byte(hs.String()), nil
+}
+
+// worse returns true if hs is more important than other. Unknown is considered between warning and critical.
+func (hs HealthSeverity) worse(other HealthSeverity) bool {
+	rank := func(s HealthSeverity) int {
+		switch s {
+		case HealthOK:
+			return 0
+		case HealthWarning:
+			return 1
+		case HealthCritical:
+			return 3
+		default:
+			return 2
+		}
+	}
+	return rank(hs) > rank(other)
+}
+
+// HealthConfig allows to customize the thresholds used by NewHealthReport(). Zero values are replaced by sane defaults.
+type HealthConfig struct {
+	StaleWarning  time.Duration        // last status update age to emit a warning (default 1h)
+	StaleCritical time.Duration        // last status update age to emit a critical (default 4h)
+	MinFirmware   map[ModuleType]int64 // optional minimum firmware version per module type, older firmwares emit a warning
+}
+
+// HealthCheck represents the result of a single check on a station or a module
+type HealthCheck struct {
+	Name     string         `json:"name"`
+	Severity HealthSeverity `json:"severity"`
+	Message  string         `json:"message"`
+}
+
+// DeviceHealth contains all the checks performed on a station or one of its modules
+type DeviceHealth struct {
+	StationID  string         `json:"station_id"`
+	ModuleID   string         `json:"module_id"` // same as StationID for the station itself
+	HomeName   string         `json:"home_name"`
+	ModuleName string         `json:"module_name"`
+	Type       ModuleType     `json:"type"`
+	Severity   HealthSeverity `json:"severity"` // worst severity of the checks
+	Checks     []HealthCheck  `json:"checks"`
+}
+
+// HealthReport is the health view of all the stations and modules of a StationDataBody
+type HealthReport struct {
+	Time     time.Time      `json:"time"`
+	Severity HealthSeverity `json:"severity"` // worst severity of the devices
+	Devices  []DeviceHealth `json:"devices"`
+}
+
+// NewHealthReport checks every station and module contained in data and returns the resulting report.
+// now is used to compute the staleness of the devices (usually time.Now()).
+func NewHealthReport(data StationDataBody, now time.Time, conf HealthConfig) (report HealthReport) {
+	if conf.StaleWarning <= 0 {
+		conf.StaleWarning = defaultHealthStaleWarning
+	}
+	if conf.StaleCritical <= 0 {
+		conf.StaleCritical = defaultHealthStaleCritical
+	}
+	report.Time = now
+	for _, station := range data.Devices {
+		report.addDevice(checkStationHealth(station, now, conf))
+		for _, module := range station.Modules {
+			device := checkModuleHealth(module, now, conf)
+			device.StationID = station.ID
+			device.HomeName = station.HomeName
+			report.addDevice(device)
+		}
+	}
+	return
+}
+
+func (hr *HealthReport) addDevice(device DeviceHealth) {
+	for _, check := range device.Checks {
+		if check.Severity.worse(device.Severity) {
+			device.Severity = check.Severity
+		}
+	}
+	if device.Severity.worse(hr.Severity) {
+		hr.Severity = device.Severity
+	}
+	hr.Devices = append(hr.Devices, device)
+}
+
+func checkStationHealth(sdbd StationDataBodyDevices, now time.Time, conf HealthConfig) (device DeviceHealth) {
+	device = DeviceHealth{
+		StationID:  sdbd.ID,
+		ModuleID:   sdbd.ID,
+		HomeName:   sdbd.HomeName,
+		ModuleName: sdbd.ModuleName,
+		Type:       sdbd.Type,
+		Checks: []HealthCheck{
+			checkReachable(sdbd.Reachable),
+			checkStaleness(now, sdbd.LastStatusStore, conf),
+			checkWiFi(sdbd.WifiStatus),
+			checkFirmware(sdbd.Type, int64(sdbd.Firmware), conf),
+		},
+	}
+	if sdbd.CO2Calibrating {
+		device.Checks = append(device.Checks, HealthCheck{
+			Name:     "co2",
+			Severity: HealthWarning,
+			Message:  "CO2 sensor is calibrating",
+		})
+	} else {
+		device.Checks = append(device.Checks, HealthCheck{
+			Name:     "co2",
+			Severity: HealthOK,
+			Message:  "CO2 sensor is not calibrating",
+		})
+	}
+	return
+}
+
+func checkModuleHealth(m Module, now time.Time, conf HealthConfig) (device DeviceHealth) {
+	return DeviceHealth{
+		ModuleID:   m.ID,
+		ModuleName: m.ModuleName,
+		Type:       m.Type,
+		Checks: []HealthCheck{
+			checkReachable(m.Reachable),
+			checkStaleness(now, m.LastSeen, conf),
+			checkRadio(m.RfStatus),
+			checkBattery(m.Type, m.BatteryVp, m.BatteryPercent),
+			checkFirmware(m.Type, m.Firmware, conf),
+		},
+	}
+}
+
+func checkReachable(reachable bool) HealthCheck {
+	if !reachable {
+		return HealthCheck{
+			Name:     "reachable",
+			Severity: HealthCritical,
+			Message:  "device is not reachable",
+		}
+	}
+	return HealthCheck{
+		Name:     "reachable",
+		Severity: HealthOK,
+		Message:  "device is reachable",
+	}
+}
+
+func checkStaleness(now, lastUpdate time.Time, conf HealthConfig) (check HealthCheck) {
+	check.Name = "last_seen"
+	if lastUpdate.IsZero() {
+		check.Severity = HealthUnknown
+		check.Message = "last status update time is unknown"
+		return
+	}
+	age := now.Sub(lastUpdate).Truncate(time.Second)
+	check.Message = fmt.Sprintf("last status update %s ago", age)
+	switch {
+	case age >= conf.StaleCritical:
+		check.Severity = HealthCritical
+	case age >= conf.StaleWarning:
+		check.Severity = HealthWarning
+	default:
+		check.Severity = HealthOK
+	}
+	return
+}
+
+func checkWiFi(quality WiFiQuality) (check HealthCheck) {
+	check.Name = "wifi"
+	check.Message = fmt.Sprintf("WiFi signal is %s (%d)", quality, quality)
+	switch {
+	case quality <= WiFiQualityAverage:
+		check.Severity = HealthOK
+	case quality <= WiFiQualityBad:
+		check.Severity = HealthWarning
+	default:
+		check.Severity = HealthCritical
+	}
+	return
+}
+
+func checkRadio(quality RadioQuality) (check HealthCheck) {
+	check.Name = "radio"
+	check.Message = fmt.Sprintf("radio signal is %s (%d)", quality, quality)
+	switch {
+	case quality <= RadioQualityMedium:
+		check.Severity = HealthOK
+	case quality <= RadioQualityLow:
+		check.Severity = HealthWarning
+	default:
+		check.Severity = HealthCritical
+	}
+	return
+}
+
+func checkBattery(mtype ModuleType, voltage, percent int64) (check HealthCheck) {
+	check.Name = "battery"
+	var low, veryLow bool
+	switch mtype {
+	case ModuleTypeAnemometer:
+		status := AnemometerBatteryStatus(voltage)
+		check.Message = fmt.Sprintf("battery is %s (%d%%, %d mV)", status, percent, voltage)
+		low = status < AnemometerBatteryMedium
+		veryLow = status < AnemometerBatteryLow
+	default:
+		status := ModulesBatteryStatus(voltage)
+		check.Message = fmt.Sprintf("battery is %s (%d%%, %d mV)", status, percent, voltage)
+		low = status < ModulesBatteryMedium
+		veryLow = status < ModulesBatteryLow
+	}
+	switch {
+	case veryLow:
+		check.Severity = HealthCritical
+	case low:
+		check.Severity = HealthWarning
+	default:
+		check.Severity = HealthOK
+	}
+	return
+}
+
+func checkFirmware(mtype ModuleType, firmware int64, conf HealthConfig) (check HealthCheck) {
+	check.Name = "firmware"
+	check.Severity = HealthOK
+	check.Message = fmt.Sprintf("firmware version %d", firmware)
+	if minimum, found := conf.MinFirmware[mtype]; found && firmware < minimum {
+		check.Severity = HealthWarning
+		check.Message = fmt.Sprintf("firmware version %d is older than %d", firmware, minimum)
+	}
+	return
+}
+
+// WriteText writes a human readable version of the report to w
+func (hr HealthReport) WriteText(w io.Writer) (err error) {
+	if _, err = fmt.Fprintf(w, "Health report at %s: %s\n", hr.Time.Format(time.RFC3339), hr.Severity); err != nil {
+		return
+	}
+	for _, device := range hr.Devices {
+		if _, err = fmt.Fprintf(w, "%s / %s (%s, %s): %s\n",
+			device.HomeName, device.ModuleName, device.Type, device.ModuleID, device.Severity); err != nil {
+			return
+		}
+		for _, check := range device.Checks {
+			if _, err = fmt.Fprintf(w, "\t[%s] %s: %s\n", check.Severity, check.Name, check.Message); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// WriteJSON writes the JSON representation of the report to w
+func (hr HealthReport) WriteJSON(w io.Writer) (err error) {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	if err = encoder.Encode(hr); err != nil {
+		err = fmt.Errorf("failed to encode the health report as JSON: %w", err)
+	}
+	return
+}
+
+// Nagios returns the report as a Nagios/Icinga plugin output along with the plugin exit code to use
+func (hr HealthReport) Nagios() (output string, exitCode int) {
+	var (
+		counts   = make(map[HealthSeverity]int, 4)
+		details  strings.Builder
+		perfdata []string
+	)
+	for _, device := range hr.Devices {
+		counts[device.Severity]++
+		for _, check := range device.Checks {
+			if check.Severity != HealthOK {
+				details.WriteString(fmt.Sprintf("%s: %s / %s: %s\n",
+					check.Severity, device.HomeName, device.ModuleName, check.Message))
+			}
+		}
+	}
+	// perfdata
+	for _, device := range hr.Devices {
+		label := strings.NewReplacer("'", "", "=", "_").Replace(device.HomeName + " " + device.ModuleName)
+		perfdata = append(perfdata, fmt.Sprintf("'%s'=%d;%d;%d;0;3", label, device.Severity, HealthWarning, HealthCritical))
+	}
+	// summary line
+	output = fmt.Sprintf("NETATMO %s - %d devices: %d critical, %d warning, %d unknown, %d ok",
+		hr.Severity, len(hr.Devices), counts[HealthCritical], counts[HealthWarning], counts[HealthUnknown], counts[HealthOK])
+	if len(perfdata) > 0 {
+		output += " | " + strings.Join(perfdata, " ")
+	}
+	if details.Len() > 0 {
+		output += "\n" + strings.TrimSuffix(details.String(), "\n")
+	}
+	exitCode = int(hr.Severity)
+	return
+}
@@ -0,0 +1,319 @@
+package weather
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	// StandardLapseRate is the standard atmosphere temperature lapse rate in °C per kilometer
+	StandardLapseRate = 6.5
+	// ASCIIGridNoData is the value used for cells without data in ASCII grids
+	ASCIIGridNoData = -9999
+)
+
+const (
+	defaultInterpolationPower  = 2
+	defaultInterpolationMaxAge = time.Hour
+)
+
+// InterpolationVariable represents the measure to interpolate on a grid
+type InterpolationVariable int
+
+const (
+	// InterpolateTemperature interpolates the outdoor temperature (°C)
+	InterpolateTemperature InterpolationVariable = iota
+	// InterpolatePressure interpolates the sea-level pressure (mbar)
+	InterpolatePressure
+	// InterpolateRain60min interpolates the rain of the last hour (mm)
+	InterpolateRain60min
+	// InterpolateRain24h interpolates the rain of the last 24 hours (mm)
+	InterpolateRain24h
+)
+
+// String implements the https://golang.org/pkg/fmt/#Stringer interface
+func (iv InterpolationVariable) String() string {
+	switch iv {
+	case InterpolateTemperature:
+		return "temperature"
+	case InterpolatePressure:
+		return "pressure"
+	case InterpolateRain60min:
+		return "rain 60min"
+	case InterpolateRain24h:
+		return "rain 24h"
+	default:
+		return "<unknown>"
+	}
+}
+
+// GoString implements the https://golang.org/pkg/fmt/#GoStringer interface
+func (iv InterpolationVariable) GoString() string {
+	return fmt.Sprintf("%s (%d)", iv.String(), iv)
+}
+
+// GridConfig describes the grid to compute with InterpolatePublicData(). Zero values of optional fields are replaced by sane defaults.
+type GridConfig struct {
+	NorthEast GeoPoint              // north east corner of the grid
+	SouthWest GeoPoint              // south west corner of the grid
+	Rows      int                   // number of rows (latitude steps)
+	Columns   int                   // number of columns (longitude steps)
+	Variable  InterpolationVariable // measure to interpolate
+	// optional
+	Power       float64       // inverse distance weighting power (default 2)
+	MaxDistance float64       // stations further than this distance (km) from a cell are ignored (0 for no limit)
+	Now         time.Time     // reference time used to discard old readings (default time.Now())
+	MaxAge      time.Duration // readings older than Now - MaxAge are ignored (default 1h)
+	// temperature only: if LapseRate is set, stations temperatures are reduced to sea level before interpolation
+	// then brought back to the cell altitude given by Elevation (or kept at sea level if Elevation is nil).
+	LapseRate float64                                          // in °C per kilometer, see StandardLapseRate
+	Elevation func(point GeoPoint) (altitude float64, ok bool) // altitude (m) of a cell center
+}
+
+// Grid is a regular lat/lon grid of interpolated values. Values[0] is the northern row and Values[x][0] the western column.
+// Cells without data are NaN.
+type Grid struct {
+	NorthEast GeoPoint
+	SouthWest GeoPoint
+	Rows      int
+	Columns   int
+	Values    [][]float64
+}
+
+// CellCenter returns the position of the center of a cell
+func (g Grid) CellCenter(row, column int) GeoPoint {
+	latStep := (g.NorthEast.Latitude - g.SouthWest.Latitude) / float64(g.Rows)
+	lonStep := (g.NorthEast.Longitude - g.SouthWest.Longitude) / float64(g.Columns)
+	return GeoPoint{
+		Latitude:  g.NorthEast.Latitude - (float64(row)+0.5)*latStep,
+		Longitude: g.SouthWest.Longitude + (float64(column)+0.5)*lonStep,
+	}
+}
+
+// Range returns the minimum and maximum values of the grid, ignoring cells without data
+func (g Grid) Range() (min, max float64) {
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, row := range g.Values {
+		for _, value := range row {
+			if math.IsNaN(value) {
+				continue
+			}
+			min = math.Min(min, value)
+			max = math.Max(max, value)
+		}
+	}
+	return
+}
+
+type interpolationSample struct {
+	point GeoPoint
+	value float64
+}
+
+// InterpolatePublicData interpolates a measure of public stations (see GetPublicData()) on a regular grid
+// using inverse distance weighting.
+func InterpolatePublicData(stations []PublicStationData, conf GridConfig) (grid Grid, err error) {
+	// verify & defaults
+	if conf.Rows <= 0 || conf.Columns <= 0 {
+		err = errors.New("grid must have at least one row and one column")
+		return
+	}
+	if conf.NorthEast.Latitude <= conf.SouthWest.Latitude || conf.NorthEast.Longitude <= conf.SouthWest.Longitude {
+		err = errors.New("north east corner must be north and east of the south west corner")
+		return
+	}
+	if conf.Power <= 0 {
+		conf.Power = defaultInterpolationPower
+	}
+	if conf.Now.IsZero() {
+		conf.Now = time.Now()
+	}
+	if conf.MaxAge <= 0 {
+		conf.MaxAge = defaultInterpolationMaxAge
+	}
+	lapse := conf.Variable == InterpolateTemperature && conf.LapseRate != 0
+	// gather samples
+	samples, err := interpolationSamples(stations, conf, lapse)
+	if err != nil {
+		return
+	}
+	if len(samples) == 0 {
+		err = fmt.Errorf("no station with a recent %s value", conf.Variable)
+		return
+	}
+	// interpolate
+	grid = Grid{
+		NorthEast: conf.NorthEast,
+		SouthWest: conf.SouthWest,
+		Rows:      conf.Rows,
+		Columns:   conf.Columns,
+		Values:    make([][]float64, conf.Rows),
+	}
+	for row := 0; row < conf.Rows; row++ {
+		grid.Values[row] = make([]float64, conf.Columns)
+		for column := 0; column < conf.Columns; column++ {
+			center := grid.CellCenter(row, column)
+			value := idw(samples, center, conf.Power, conf.MaxDistance)
+			if lapse && conf.Elevation != nil && !math.IsNaN(value) {
+				if altitude, ok := conf.Elevation(center); ok {
+					value -= conf.LapseRate * altitude / 1000
+				}
+			}
+			grid.Values[row][column] = value
+		}
+	}
+	return
+}
+
+func interpolationSamples(stations []PublicStationData, conf GridConfig, lapse bool) (samples []interpolationSample, err error) {
+	oldest := conf.Now.Add(-conf.MaxAge)
+	samples = make([]interpolationSample, 0, len(stations))
+	for _, station := range stations {
+		point, ok := station.Place.Point()
+		if !ok {
+			continue
+		}
+		var (
+			value float64
+			at    time.Time
+		)
+		switch conf.Variable {
+		case InterpolateTemperature:
+			if station.Outdoor == nil || len(station.Outdoor.Measures) == 0 {
+				continue
+			}
+			last := station.Outdoor.Measures[len(station.Outdoor.Measures)-1]
+			value, at = last.Temperature, last.Time
+			if lapse {
+				value += conf.LapseRate * station.Place.Altitude / 1000
+			}
+		case InterpolatePressure:
+			if len(station.Pressure) == 0 {
+				continue
+			}
+			last := station.Pressure[len(station.Pressure)-1]
+			value, at = last.Pressure, last.Time
+		case InterpolateRain60min, InterpolateRain24h:
+			if station.Rain == nil {
+				continue
+			}
+			value, at = station.Rain.Measures.Rain60min, station.Rain.Measures.Time
+			if conf.Variable == InterpolateRain24h {
+				value = station.Rain.Measures.Rain24h
+			}
+		default:
+			err = fmt.Errorf("unknown interpolation variable: %d", conf.Variable)
+			return
+		}
+		if at.Before(oldest) {
+			continue
+		}
+		samples = append(samples, interpolationSample{
+			point: point,
+			value: value,
+		})
+	}
+	return
+}
+
+// idw computes the inverse distance weighted value at point. Returns NaN if no sample is close enough.
+func idw(samples []interpolationSample, point GeoPoint, power, maxDistance float64) float64 {
+	var weights, sum float64
+	for _, sample := range samples {
+		distance := point.Distance(sample.point)
+		if maxDistance > 0 && distance > maxDistance {
+			continue
+		}
+		if distance < 1e-6 {
+			return sample.value
+		}
+		weight := 1 / math.Pow(distance, power)
+		weights += weight
+		sum += weight * sample.value
+	}
+	if weights == 0 {
+		return math.NaN()
+	}
+	return sum / weights
+}
+
+// WriteASCIIGrid writes the grid using the Esri ASCII raster format. Cells without data are written as ASCIIGridNoData.
+// As lat/lon cells are rarely square, the "dx"/"dy" header variant (understood by GDAL) is used when needed.
+func (g Grid) WriteASCIIGrid(w io.Writer) (err error) {
+	buff := bufio.NewWriter(w)
+	dx := (g.NorthEast.Longitude - g.SouthWest.Longitude) / float64(g.Columns)
+	dy := (g.NorthEast.Latitude - g.SouthWest.Latitude) / float64(g.Rows)
+	fmt.Fprintf(buff, "ncols %d\nnrows %d\nxllcorner %s\nyllcorner %s\n", g.Columns, g.Rows,
+		strconv.FormatFloat(g.SouthWest.Longitude, 'f', -1, 64), strconv.FormatFloat(g.SouthWest.Latitude, 'f', -1, 64))
+	if math.Abs(dx-dy) < 1e-12 {
+		fmt.Fprintf(buff, "cellsize %s\n", strconv.FormatFloat(dx, 'f', -1, 64))
+	} else {
+		fmt.Fprintf(buff, "dx %s\ndy %s\n", strconv.FormatFloat(dx, 'f', -1, 64), strconv.FormatFloat(dy, 'f', -1, 64))
+	}
+	fmt.Fprintf(buff, "NODATA_value %d\n", ASCIIGridNoData)
+	for _, row := range g.Values {
+		for column, value := range row {
+			if column > 0 {
+				buff.WriteByte(' ')
+			}
+			if math.IsNaN(value) {
+				buff.WriteString(strconv.Itoa(ASCIIGridNoData))
+			} else {
+				buff.WriteString(strconv.FormatFloat(value, 'f', 2, 64))
+			}
+		}
+		buff.WriteByte('\n')
+	}
+	if err = buff.Flush(); err != nil {
+		err = fmt.Errorf("failed to write ASCII grid: %w", err)
+	}
+	return
+}
+
+// WritePNG renders the grid as a PNG image (one pixel per cell) using a blue (min) to red (max) color scale.
+// Cells without data are transparent.
+func (g Grid) WritePNG(w io.Writer) (err error) {
+	min, max := g.Range()
+	img := image.NewNRGBA(image.Rect(0, 0, g.Columns, g.Rows))
+	for row, values := range g.Values {
+		for column, value := range values {
+			if math.IsNaN(value) {
+				continue
+			}
+			ratio := 0.5
+			if max > min {
+				ratio = (value - min) / (max - min)
+			}
+			img.SetNRGBA(column, row, heatColor(ratio))
+		}
+	}
+	if err = png.Encode(w, img); err != nil {
+		err = fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return
+}
+
+// heatColor returns a color going from blue (0) to green (0.5) to red (1)
+func heatColor(ratio float64) color.NRGBA {
+	ratio = math.Max(0, math.Min(1, ratio))
+	if ratio < 0.5 {
+		return color.NRGBA{
+			G: uint8(ratio * 2 * 255),
+			B: uint8((1 - ratio*2) * 255),
+			A: 255,
+		}
+	}
+	return color.NRGBA{
+		R: uint8((ratio - 0.5) * 2 * 255),
+		G: uint8((1 - (ratio-0.5)*2) * 255),
+		A: 255,
+	}
+}
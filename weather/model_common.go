@@ -1,6 +1,9 @@
 package weather
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // ModulesBatteryStatus represents the battery status for additionnal modules
 type ModulesBatteryStatus int
@@ -128,6 +131,26 @@ const (
 	TrendStable Trend = "stable"
 )
 
+/*
+	JSON helpers
+*/
+
+// timeFromUnix converts a Netatmo payload timestamp to a time. A missing (0) timestamp gives the zero time.
+func timeFromUnix(timestamp int64) time.Time {
+	if timestamp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(timestamp, 0)
+}
+
+// unixTimestamp converts a time back to the Netatmo payload representation (0 for zero time)
+func unixTimestamp(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
 /*
 	TODO organize
 // */
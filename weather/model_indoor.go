@@ -8,17 +8,22 @@ import (
 
 // IndoorModuleDashboardData struct for IndoorModuleDashboardData
 type IndoorModuleDashboardData struct {
-	Time        time.Time ``                   // date when data was measured
+	Time        time.Time `json:"-"`           // date when data was measured
 	Temperature float64   `json:"Temperature"` // temperature (in °C)
 	CO2         int64     `json:"CO2"`         // CO2 level (in ppm)
 	Humidity    int64     `json:"Humidity"`    // humidity (in %)
 	MinTemp     float64   `json:"min_temp"`    // maximum temperature measured
 	MaxTemp     float64   `json:"max_temp"`    // maximum temperature measured
-	DateMinTemp time.Time ``                   // date of minimum temperature measured
-	DateMaxTemp time.Time ``                   // date of maximum temperature measured
+	DateMinTemp time.Time `json:"-"`           // date of minimum temperature measured
+	DateMaxTemp time.Time `json:"-"`           // date of maximum temperature measured
 	TempTrend   Trend     `json:"temp_trend"`  // trend for the last 12h (up, down, stable: see Trend const values)
 }
 
+// IsSet returns true if the dashboard contains values (false if the payload did not contain them)
+func (imdd IndoorModuleDashboardData) IsSet() bool {
+	return !imdd.Time.IsZero()
+}
+
 // UnmarshalJSON allows to automatically convert data to go types
 func (imdd *IndoorModuleDashboardData) UnmarshalJSON(data []byte) (err error) {
 	type OriginalUnmarshal IndoorModuleDashboardData
@@ -36,8 +41,29 @@ func (imdd *IndoorModuleDashboardData) UnmarshalJSON(data []byte) (err error) {
 		return
 	}
 	// convert
-	imdd.Time = time.Unix(tmp.TimeUTC, 0)
-	imdd.DateMinTemp = time.Unix(tmp.DateMinTemp, 0)
-	imdd.DateMaxTemp = time.Unix(tmp.DateMaxTemp, 0)
+	imdd.Time = timeFromUnix(tmp.TimeUTC)
+	imdd.DateMinTemp = timeFromUnix(tmp.DateMinTemp)
+	imdd.DateMaxTemp = timeFromUnix(tmp.DateMaxTemp)
+	return
+}
+
+// MarshalJSON allows to convert go types back to the original payload
+func (imdd IndoorModuleDashboardData) MarshalJSON() (data []byte, err error) {
+	type OriginalMarshal IndoorModuleDashboardData
+	tmp := struct {
+		TimeUTC     int64 `json:"time_utc"`      // timestamp when data was measured
+		DateMinTemp int64 `json:"date_min_temp"` // timestamp of minimum temperature measured
+		DateMaxTemp int64 `json:"date_max_temp"` // timestamp of maximum temperature measured
+		OriginalMarshal
+	}{
+		TimeUTC:         unixTimestamp(imdd.Time),
+		DateMinTemp:     unixTimestamp(imdd.DateMinTemp),
+		DateMaxTemp:     unixTimestamp(imdd.DateMaxTemp),
+		OriginalMarshal: OriginalMarshal(imdd),
+	}
+	// Marshal the tmp struct
+	if data, err = json.Marshal(tmp); err != nil {
+		err = fmt.Errorf("can not marshal Indoor dashboard tmp struct: %w", err)
+	}
 	return
 }
@@ -12,19 +12,19 @@ type Module struct {
 	Type                 ModuleType                  `json:"type"`            // type of module (see ModuleType const values)
 	ModuleName           string                      `json:"module_name"`     // user set name of the module
 	DataType             []ModuleDataType            `json:"data_type"`       // array of data measured by the device (see ModuleDataType const values)
-	LastSetup            time.Time                   ``                       // date of the last installation
+	LastSetup            time.Time                   `json:"-"`               // date of the last installation
 	Reachable            bool                        `json:"reachable"`       // true if the station connected to Netatmo cloud within the last 4 hours
 	Firmware             int64                       `json:"firmware"`        // version of the software
-	LastMessage          time.Time                   ``                       // date of the last measure update
-	LastSeen             time.Time                   ``                       // date of the last status update
+	LastMessage          time.Time                   `json:"-"`               // date of the last measure update
+	LastSeen             time.Time                   `json:"-"`               // date of the last status update
 	RfStatus             RadioQuality                `json:"rf_status"`       // current radio status per module (see RadioQuality const values)
 	BatteryVp            int64                       `json:"battery_vp"`      // current battery status per module (legacy, see BatteryPercent)
 	BatteryPercent       int64                       `json:"battery_percent"` // percentage of battery remaining (10=low)
-	DashboardDataOutdoor *OutdoorModuleDashboardData ``                       // values summary if module type is outdoor and is reachable
-	DashboardDataWind    *WindModuleDashboardData    ``                       // values summary if module type is wind and is reachable
-	DashboardDataRain    *RainModuleDashboardData    ``                       // values summary if module type is rain and is reachable
-	DashboardDataIndoor  *IndoorModuleDashboardData  ``                       // values summary if module type is indoor and is reachable
-	DashboardDataRaw     json.RawMessage             ``                       // in case type auto detect has failed, raw dashboard will be kept here (module must still be reachable)
+	DashboardDataOutdoor *OutdoorModuleDashboardData `json:"-"`               // values summary if module type is outdoor and is reachable
+	DashboardDataWind    *WindModuleDashboardData    `json:"-"`               // values summary if module type is wind and is reachable
+	DashboardDataRain    *RainModuleDashboardData    `json:"-"`               // values summary if module type is rain and is reachable
+	DashboardDataIndoor  *IndoorModuleDashboardData  `json:"-"`               // values summary if module type is indoor and is reachable
+	DashboardDataRaw     json.RawMessage             `json:"-"`               // in case type auto detect has failed, raw dashboard will be kept here (module must still be reachable)
 }
 
 // UnmarshalJSON allows to automatically convert data to go types
@@ -46,11 +46,11 @@ func (m *Module) UnmarshalJSON(data []byte) (err error) {
 		return
 	}
 	// Convert
-	m.LastSetup = time.Unix(tmp.LastSetup, 0)
-	m.LastMessage = time.Unix(tmp.LastMessage, 0)
-	m.LastSeen = time.Unix(tmp.LastSeen, 0)
+	m.LastSetup = timeFromUnix(tmp.LastSetup)
+	m.LastMessage = timeFromUnix(tmp.LastMessage)
+	m.LastSeen = timeFromUnix(tmp.LastSeen)
 	// Handle module type to select the right dashboard
-	if m.Reachable {
+	if m.Reachable && len(tmp.DashboardDataRaw) > 0 && string(tmp.DashboardDataRaw) != "null" {
 		switch tmp.Type {
 		case ModuleTypeOutdoor:
 			m.DashboardDataOutdoor = new(OutdoorModuleDashboardData)
@@ -87,3 +87,39 @@ func (m *Module) UnmarshalJSON(data []byte) (err error) {
 	}
 	return
 }
+
+// MarshalJSON allows to convert go types back to the original payload
+func (m Module) MarshalJSON() (data []byte, err error) {
+	// Add tmp type
+	type OriginalMarshal Module
+	tmp := struct {
+		LastSetup     int64       `json:"last_setup"`
+		LastMessage   int64       `json:"last_message"`
+		LastSeen      int64       `json:"last_seen"`
+		DashboardData interface{} `json:"dashboard_data,omitempty"`
+		OriginalMarshal
+	}{
+		LastSetup:       unixTimestamp(m.LastSetup),
+		LastMessage:     unixTimestamp(m.LastMessage),
+		LastSeen:        unixTimestamp(m.LastSeen),
+		OriginalMarshal: OriginalMarshal(m),
+	}
+	// Select the dashboard matching the module type
+	switch {
+	case m.DashboardDataOutdoor != nil:
+		tmp.DashboardData = m.DashboardDataOutdoor
+	case m.DashboardDataWind != nil:
+		tmp.DashboardData = m.DashboardDataWind
+	case m.DashboardDataRain != nil:
+		tmp.DashboardData = m.DashboardDataRain
+	case m.DashboardDataIndoor != nil:
+		tmp.DashboardData = m.DashboardDataIndoor
+	case len(m.DashboardDataRaw) > 0:
+		tmp.DashboardData = m.DashboardDataRaw
+	}
+	// Marshal the tmp struct
+	if data, err = json.Marshal(tmp); err != nil {
+		err = fmt.Errorf("failed to marshal the temporary Module struct: %w", err)
+	}
+	return
+}
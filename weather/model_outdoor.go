@@ -8,16 +8,21 @@ import (
 
 // OutdoorModuleDashboardData struct for OutdoorModuleDashboardData
 type OutdoorModuleDashboardData struct {
-	Time        time.Time ``                   // date when data was measured
+	Time        time.Time `json:"-"`           // date when data was measured
 	Temperature float64   `json:"Temperature"` // temperature (in °C)
 	Humidity    int64     `json:"Humidity"`    // humidity (in %)
 	MinTemp     float64   `json:"min_temp"`    // minimum temperature measured
 	MaxTemp     float64   `json:"max_temp"`    // maximum temperature measured
-	DateMinTemp time.Time ``                   // date of minimum temperature measured
-	DateMaxTemp time.Time ``                   // date of maximum temperature measured
+	DateMinTemp time.Time `json:"-"`           // date of minimum temperature measured
+	DateMaxTemp time.Time `json:"-"`           // date of maximum temperature measured
 	TempTrend   Trend     `json:"temp_trend"`  // trend for the last 12h (up, down, stable: see Trend const values)
 }
 
+// IsSet returns true if the dashboard contains values (false if the payload did not contain them)
+func (omdd OutdoorModuleDashboardData) IsSet() bool {
+	return !omdd.Time.IsZero()
+}
+
 // UnmarshalJSON allows to automatically convert data to go types
 func (omdd *OutdoorModuleDashboardData) UnmarshalJSON(data []byte) (err error) {
 	type OriginalUnmarshal OutdoorModuleDashboardData
@@ -35,8 +40,29 @@ func (omdd *OutdoorModuleDashboardData) UnmarshalJSON(data []byte) (err error) {
 		return
 	}
 	// convert
-	omdd.Time = time.Unix(tmp.TimeUTC, 0)
-	omdd.DateMinTemp = time.Unix(tmp.DateMinTemp, 0)
-	omdd.DateMaxTemp = time.Unix(tmp.DateMaxTemp, 0)
+	omdd.Time = timeFromUnix(tmp.TimeUTC)
+	omdd.DateMinTemp = timeFromUnix(tmp.DateMinTemp)
+	omdd.DateMaxTemp = timeFromUnix(tmp.DateMaxTemp)
+	return
+}
+
+// MarshalJSON allows to convert go types back to the original payload
+func (omdd OutdoorModuleDashboardData) MarshalJSON() (data []byte, err error) {
+	type OriginalMarshal OutdoorModuleDashboardData
+	tmp := struct {
+		TimeUTC     int64 `json:"time_utc"`      // timestamp when data was measured
+		DateMinTemp int64 `json:"date_min_temp"` // timestamp of minimum temperature measured
+		DateMaxTemp int64 `json:"date_max_temp"` // timestamp of maximum temperature measured
+		OriginalMarshal
+	}{
+		TimeUTC:         unixTimestamp(omdd.Time),
+		DateMinTemp:     unixTimestamp(omdd.DateMinTemp),
+		DateMaxTemp:     unixTimestamp(omdd.DateMaxTemp),
+		OriginalMarshal: OriginalMarshal(omdd),
+	}
+	// Marshal the tmp struct
+	if data, err = json.Marshal(tmp); err != nil {
+		err = fmt.Errorf("can not marshal Outdoor dashboard tmp struct: %w", err)
+	}
 	return
 }
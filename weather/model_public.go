@@ -14,10 +14,10 @@ type PublicStationData struct {
 	ID       string                          `json:"_id"`
 	Place    Place                           `json:"place"`
 	Mark     int                             `json:"mark"`
-	Pressure PublicStationDataPressureValues ``
-	Outdoor  *PublicOutdoorModule            ``
-	Wind     *PublicWindModule               ``
-	Rain     *PublicRainModule               ``
+	Pressure PublicStationDataPressureValues `json:"-"`
+	Outdoor  *PublicOutdoorModule            `json:"-"`
+	Wind     *PublicWindModule               `json:"-"`
+	Rain     *PublicRainModule               `json:"-"`
 }
 
 // UnmarshalJSON allows to create a proper payloade on the fly during JSON unmarshaling
@@ -92,6 +92,46 @@ func (pdb *PublicStationData) UnmarshalJSON(data []byte) (err error) {
 	return
 }
 
+// MarshalJSON allows to convert go types back to the original payload
+func (pdb PublicStationData) MarshalJSON() (data []byte, err error) {
+	// Add tmp type
+	type OriginalMarshal PublicStationData
+	tmp := struct {
+		Measures    map[string]interface{} `json:"measures"`     // key is MAC addr (IDs), value is dynamic payload given module type
+		Modules     []string               `json:"modules"`      // list of modules MAC addr (IDs)
+		ModuleTypes map[string]ModuleType  `json:"module_types"` // key is MAC addr (IDs) value is module type
+		OriginalMarshal
+	}{
+		Measures:        make(map[string]interface{}, 4),
+		Modules:         make([]string, 0, 3),
+		ModuleTypes:     make(map[string]ModuleType, 3),
+		OriginalMarshal: OriginalMarshal(pdb),
+	}
+	addModule := func(moduleID string, mtype ModuleType, measures interface{}) {
+		tmp.Measures[moduleID] = measures
+		tmp.Modules = append(tmp.Modules, moduleID)
+		tmp.ModuleTypes[moduleID] = mtype
+	}
+	// Rebuild each module payload
+	if len(pdb.Pressure) > 0 {
+		tmp.Measures[pdb.ID] = marshalPublicStationData(pdb.Pressure)
+	}
+	if pdb.Outdoor != nil {
+		addModule(pdb.Outdoor.ID, ModuleTypeOutdoor, marshalPublicOutdoorData(pdb.Outdoor.Measures))
+	}
+	if pdb.Wind != nil {
+		addModule(pdb.Wind.ID, ModuleTypeAnemometer, pdb.Wind.Measures)
+	}
+	if pdb.Rain != nil {
+		addModule(pdb.Rain.ID, ModuleTypeRainGauge, pdb.Rain.Measures)
+	}
+	// Marshal the tmp struct
+	if data, err = json.Marshal(tmp); err != nil {
+		err = fmt.Errorf("failed to marshal the temporary PublicStationData struct: %w", err)
+	}
+	return
+}
+
 type publicStationDataMeasures struct {
 	Measures map[string][]float64 `json:"res"`
 	Types    []string             `json:"type"`
@@ -137,6 +177,17 @@ func unmarshalPublicStationData(data json.RawMessage) (pressure PublicStationDat
 	return
 }
 
+func marshalPublicStationData(pressure PublicStationDataPressureValues) (measures publicStationDataMeasures) {
+	measures = publicStationDataMeasures{
+		Measures: make(map[string][]float64, len(pressure)),
+		Types:    []string{strings.ToLower(string(ModuleDataTypePressure))},
+	}
+	for _, value := range pressure {
+		measures.Measures[strconv.FormatInt(unixTimestamp(value.Time), 10)] = []float64{value.Pressure}
+	}
+	return
+}
+
 func unmarshalPublicOutdoorData(data json.RawMessage) (outdoorData PublicStationDataOutdoorValues, err error) {
 	// Unmarshall station data
 	var mainDataTmp publicStationDataMeasures
@@ -195,6 +246,20 @@ func unmarshalPublicOutdoorData(data json.RawMessage) (outdoorData PublicStation
 	return
 }
 
+func marshalPublicOutdoorData(outdoorData PublicStationDataOutdoorValues) (measures publicStationDataMeasures) {
+	measures = publicStationDataMeasures{
+		Measures: make(map[string][]float64, len(outdoorData)),
+		Types: []string{
+			strings.ToLower(string(ModuleDataTypeTemperature)),
+			strings.ToLower(string(ModuleDataTypeHumidity)),
+		},
+	}
+	for _, value := range outdoorData {
+		measures.Measures[strconv.FormatInt(unixTimestamp(value.Time), 10)] = []float64{value.Temperature, float64(value.Humidity)}
+	}
+	return
+}
+
 // PublicOutdoorModule contains all the public informations for an outdoor module
 type PublicOutdoorModule struct {
 	ID       string
@@ -8,12 +8,17 @@ import (
 
 // RainMeasures holds measures for the RainGauge module
 type RainMeasures struct {
-	Time      time.Time `` // not in this form on the orignal payload
+	Time      time.Time `json:"-"` // not in this form on the orignal payload
 	Rain60min float64   `json:"rain_60min"`
 	Rain24h   float64   `json:"rain_24h"`
 	RainLive  float64   `json:"rain_live"`
 }
 
+// IsSet returns true if the measures have been set (false if the payload did not contain them)
+func (rm RainMeasures) IsSet() bool {
+	return !rm.Time.IsZero()
+}
+
 // UnmarshalJSON allows to create a proper payloade on the fly during JSON unmarshaling
 func (rm *RainMeasures) UnmarshalJSON(data []byte) (err error) {
 	// Add tmp type
@@ -30,18 +35,41 @@ func (rm *RainMeasures) UnmarshalJSON(data []byte) (err error) {
 		return
 	}
 	// convert
-	rm.Time = time.Unix(int64(tmp.RainTimestamp), 0)
+	rm.Time = timeFromUnix(int64(tmp.RainTimestamp))
+	return
+}
+
+// MarshalJSON allows to convert go types back to the original payload
+func (rm RainMeasures) MarshalJSON() (data []byte, err error) {
+	// Add tmp type
+	type OriginalMarshal RainMeasures
+	tmp := struct {
+		RainTimestamp int64 `json:"rain_timeutc"`
+		OriginalMarshal
+	}{
+		RainTimestamp:   unixTimestamp(rm.Time),
+		OriginalMarshal: OriginalMarshal(rm),
+	}
+	// Marshal the tmp struct
+	if data, err = json.Marshal(tmp); err != nil {
+		err = fmt.Errorf("failed to marshal the temporary RainMeasures struct: %w", err)
+	}
 	return
 }
 
 // RainModuleDashboardData struct for RainModuleDashboardData
 type RainModuleDashboardData struct {
-	Time      time.Time ``                   // date when data was measured
+	Time      time.Time `json:"-"`           // date when data was measured
 	Rain      float64   `json:"Rain"`        // rain in mm
 	SumRain24 float64   `json:"sum_rain_24"` // rain measured for past 24h(mm)
 	SumRain1  float64   `json:"sum_rain_1"`  // rain measured for the last hour (mm)
 }
 
+// IsSet returns true if the dashboard contains values (false if the payload did not contain them)
+func (rmdd RainModuleDashboardData) IsSet() bool {
+	return !rmdd.Time.IsZero()
+}
+
 // UnmarshalJSON allows to automatically convert data to go types
 func (rmdd *RainModuleDashboardData) UnmarshalJSON(data []byte) (err error) {
 	type OriginalUnmarshal RainModuleDashboardData
@@ -57,6 +85,23 @@ func (rmdd *RainModuleDashboardData) UnmarshalJSON(data []byte) (err error) {
 		return
 	}
 	// convert
-	rmdd.Time = time.Unix(tmp.TimeUTC, 0)
+	rmdd.Time = timeFromUnix(tmp.TimeUTC)
+	return
+}
+
+// MarshalJSON allows to convert go types back to the original payload
+func (rmdd RainModuleDashboardData) MarshalJSON() (data []byte, err error) {
+	type OriginalMarshal RainModuleDashboardData
+	tmp := struct {
+		TimeUTC int64 `json:"time_utc"` // timestamp when data was measured
+		OriginalMarshal
+	}{
+		TimeUTC:         unixTimestamp(rmdd.Time),
+		OriginalMarshal: OriginalMarshal(rmdd),
+	}
+	// Marshal the tmp struct
+	if data, err = json.Marshal(tmp); err != nil {
+		err = fmt.Errorf("can not marshal Rain dashboard tmp struct: %w", err)
+	}
 	return
 }
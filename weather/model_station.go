@@ -9,10 +9,10 @@ import (
 // StationDataBodyDevices struct for StationDataBodyDevices
 type StationDataBodyDevices struct {
 	ID              string                      `json:"_id"`             // uniq ID of the module (MAC address)
-	DateSetup       time.Time                   ``                       // date when the weather station was set up
-	LastSetup       time.Time                   ``                       // timestamp of the last installation
-	LastStatusStore time.Time                   ``                       // timestamp of the last status update
-	LastUpgrade     time.Time                   ``                       // timestamp of the last upgrade
+	DateSetup       time.Time                   `json:"-"`               // date when the weather station was set up
+	LastSetup       time.Time                   `json:"-"`               // timestamp of the last installation
+	LastStatusStore time.Time                   `json:"-"`               // timestamp of the last status update
+	LastUpgrade     time.Time                   `json:"-"`               // timestamp of the last upgrade
 	Type            ModuleType                  `json:"type"`            // type of the device (should alway be ModuleTypeStation const value)
 	ModuleName      string                      `json:"module_name"`     // name of the module
 	Firmware        int                         `json:"firmware"`        // version of the software
@@ -48,10 +48,43 @@ func (sdbd *StationDataBodyDevices) UnmarshalJSON(data []byte) (err error) {
 		return
 	}
 	// Convert timestamps
-	sdbd.DateSetup = time.Unix(tmp.DateSetup, 0)
-	sdbd.LastSetup = time.Unix(tmp.LastSetup, 0)
-	sdbd.LastStatusStore = time.Unix(tmp.LastStatusStore, 0)
-	sdbd.LastUpgrade = time.Unix(tmp.LastUpgrade, 0)
+	sdbd.DateSetup = timeFromUnix(tmp.DateSetup)
+	sdbd.LastSetup = timeFromUnix(tmp.LastSetup)
+	sdbd.LastStatusStore = timeFromUnix(tmp.LastStatusStore)
+	sdbd.LastUpgrade = timeFromUnix(tmp.LastUpgrade)
+	// Dashboard values are only meaningful when the station is reachable
+	if !sdbd.Reachable {
+		sdbd.DashboardData = DashboardDataWeatherStation{}
+	}
+	return
+}
+
+// MarshalJSON allows to convert go types back to the original payload
+func (sdbd StationDataBodyDevices) MarshalJSON() (data []byte, err error) {
+	// Add tmp type
+	type OriginalMarshal StationDataBodyDevices
+	tmp := struct {
+		DateSetup       int64 `json:"date_setup"`        // date when the weather station was set up
+		LastSetup       int64 `json:"last_setup"`        // timestamp of the last installation
+		LastStatusStore int64 `json:"last_status_store"` // timestamp of the last status update
+		LastUpgrade     int64 `json:"last_upgrade"`      // timestamp of the last upgrade
+		// values summary, omitted when not set (as for unreachable stations)
+		DashboardData *DashboardDataWeatherStation `json:"dashboard_data,omitempty"`
+		OriginalMarshal
+	}{
+		DateSetup:       unixTimestamp(sdbd.DateSetup),
+		LastSetup:       unixTimestamp(sdbd.LastSetup),
+		LastStatusStore: unixTimestamp(sdbd.LastStatusStore),
+		LastUpgrade:     unixTimestamp(sdbd.LastUpgrade),
+		OriginalMarshal: OriginalMarshal(sdbd),
+	}
+	if sdbd.DashboardData.IsSet() {
+		tmp.DashboardData = &sdbd.DashboardData
+	}
+	// Marshal the tmp struct
+	if data, err = json.Marshal(tmp); err != nil {
+		err = fmt.Errorf("failed to marshal the temporary StationDataBodyDevices struct: %w", err)
+	}
 	return
 }
 
@@ -88,10 +121,10 @@ func (wq WiFiQuality) GoString() string {
 
 // Place struct for Place
 type Place struct {
-	Timezone *time.Location ``                // Timezone
+	Timezone *time.Location `json:"-"`        // Timezone
 	Country  string         `json:"country"`  // Country
 	Altitude float64        `json:"altitude"` // Altitude
-	Location []float64      `json:"location"` // Lat, Long
+	Location []float64      `json:"location"` // Long, Lat
 }
 
 // UnmarshalJSON allows to automatically convert data to go types
@@ -108,16 +141,39 @@ func (p *Place) UnmarshalJSON(data []byte) (err error) {
 		err = fmt.Errorf("can not unmarshall into Indoor dashboard tmp struct: %w", err)
 		return
 	}
-	// Convert
+	// Convert (LoadLocation would return UTC for an absent timezone)
+	if tmp.Timezone == "" {
+		p.Timezone = nil
+		return
+	}
 	if p.Timezone, err = time.LoadLocation(tmp.Timezone); err != nil {
 		return fmt.Errorf("can not parse the Timezone: %w", err)
 	}
 	return
 }
 
+// MarshalJSON allows to convert go types back to the original payload
+func (p Place) MarshalJSON() (data []byte, err error) {
+	type OriginalMarshal Place
+	tmp := struct {
+		Timezone string `json:"timezone,omitempty"` // Timezone (omitted if unknown)
+		OriginalMarshal
+	}{
+		OriginalMarshal: OriginalMarshal(p),
+	}
+	if p.Timezone != nil {
+		tmp.Timezone = p.Timezone.String()
+	}
+	// Marshal the tmp struct
+	if data, err = json.Marshal(tmp); err != nil {
+		err = fmt.Errorf("can not marshal the Place tmp struct: %w", err)
+	}
+	return
+}
+
 // DashboardDataWeatherstation Weather - Weather station, getstationdata
 type DashboardDataWeatherStation struct {
-	Time             time.Time ``                        // time when data was measured
+	Time             time.Time `json:"-"`                // time when data was measured
 	Temperature      float64   `json:"Temperature"`      // temperature (in °C)
 	CO2              int       `json:"CO2"`              // CO2 level (in ppm)
 	Humidity         int       `json:"Humidity"`         // humidity (in %)
@@ -125,21 +181,26 @@ type DashboardDataWeatherStation struct {
 	Pressure         float64   `json:"Pressure"`         // surface pressure in mbar
 	AbsolutePressure float64   `json:"AbsolutePressure"` // sea-level pressure in mbar
 	TempMin          float64   `json:"min_temp"`         // minimum temperature measured
-	TempMinDate      time.Time ``                        // date of minimum temperature measured
+	TempMinDate      time.Time `json:"-"`                // date of minimum temperature measured
 	TempMax          float64   `json:"max_temp"`         // maximum temperature measured
-	TempMaxDate      time.Time ``                        // date of maximum temperature measured
+	TempMaxDate      time.Time `json:"-"`                // date of maximum temperature measured
 	TempTrend        Trend     `json:"temp_trend"`       // trend for the last 12h (up, down, stable)
 	PressureTrend    Trend     `json:"pressure_trend"`   // trend for the last 12h (up, down, stable)
 }
 
+// IsSet returns true if the dashboard contains values (false if the station was unreachable)
+func (ddws DashboardDataWeatherStation) IsSet() bool {
+	return !ddws.Time.IsZero()
+}
+
 // UnmarshalJSON allows to create a proper payloade on the fly during JSON unmarshaling
 func (ddws *DashboardDataWeatherStation) UnmarshalJSON(data []byte) (err error) {
 	// Add tmp type
 	type OriginalUnmarshal DashboardDataWeatherStation
 	tmp := struct {
 		TimeUTC     int64   `json:"time_utc"`      // timestamp when data was measured
-		DateMinTemp float32 `json:"date_min_temp"` // date of minimum temperature measured
-		DateMaxTemp float32 `json:"date_max_temp"` // date of maximum temperature measured
+		DateMinTemp float64 `json:"date_min_temp"` // date of minimum temperature measured
+		DateMaxTemp float64 `json:"date_max_temp"` // date of maximum temperature measured
 		*OriginalUnmarshal
 	}{
 		OriginalUnmarshal: (*OriginalUnmarshal)(ddws),
@@ -150,8 +211,30 @@ func (ddws *DashboardDataWeatherStation) UnmarshalJSON(data []byte) (err error)
 		return
 	}
 	// Convert timestamps
-	ddws.Time = time.Unix(tmp.TimeUTC, 0)
-	ddws.TempMinDate = time.Unix(int64(tmp.DateMinTemp), 0)
-	ddws.TempMaxDate = time.Unix(int64(tmp.DateMaxTemp), 0)
+	ddws.Time = timeFromUnix(tmp.TimeUTC)
+	ddws.TempMinDate = timeFromUnix(int64(tmp.DateMinTemp))
+	ddws.TempMaxDate = timeFromUnix(int64(tmp.DateMaxTemp))
+	return
+}
+
+// MarshalJSON allows to convert go types back to the original payload
+func (ddws DashboardDataWeatherStation) MarshalJSON() (data []byte, err error) {
+	// Add tmp type
+	type OriginalMarshal DashboardDataWeatherStation
+	tmp := struct {
+		TimeUTC     int64 `json:"time_utc"`      // timestamp when data was measured
+		DateMinTemp int64 `json:"date_min_temp"` // date of minimum temperature measured
+		DateMaxTemp int64 `json:"date_max_temp"` // date of maximum temperature measured
+		OriginalMarshal
+	}{
+		TimeUTC:         unixTimestamp(ddws.Time),
+		DateMinTemp:     unixTimestamp(ddws.TempMinDate),
+		DateMaxTemp:     unixTimestamp(ddws.TempMaxDate),
+		OriginalMarshal: OriginalMarshal(ddws),
+	}
+	// Marshal the tmp struct
+	if data, err = json.Marshal(tmp); err != nil {
+		err = fmt.Errorf("failed to marshal the temporary DashboardDataWeatherStation struct: %w", err)
+	}
 	return
 }
@@ -0,0 +1,282 @@
+package weather
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// stationDataFixture is a getstationsdata body: a reachable station with all the module types and an
+// unreachable station without time zone nor dashboards. Zero timestamps are sent as 0 by the API.
+const stationDataFixture = `{
+	"devices": [
+		{
+			"_id": "70:ee:50:00:00:01",
+			"date_setup": 1577836800,
+			"last_setup": 1577836800,
+			"last_status_store": 1622548800,
+			"last_upgrade": 0,
+			"type": "NAMain",
+			"module_name": "Living room",
+			"firmware": 181,
+			"wifi_status": 56,
+			"reachable": true,
+			"co2_calibrating": false,
+			"data_type": ["Temperature", "CO2", "Humidity", "Noise", "Pressure"],
+			"place": {"timezone": "Europe/Paris", "country": "FR", "altitude": 35, "location": [2.35, 48.85]},
+			"read_only": false,
+			"home_id": "5e0a000000000000000000aa",
+			"home_name": "Home",
+			"dashboard_data": {
+				"time_utc": 1622548790,
+				"Temperature": 21.3,
+				"CO2": 650,
+				"Humidity": 48,
+				"Noise": 38,
+				"Pressure": 1015.2,
+				"AbsolutePressure": 1002.1,
+				"min_temp": 19.8,
+				"max_temp": 22.1,
+				"date_min_temp": 1622520000,
+				"date_max_temp": 1622540000,
+				"temp_trend": "up",
+				"pressure_trend": "stable"
+			},
+			"modules": [
+				{
+					"_id": "02:00:00:00:00:02",
+					"type": "NAModule1",
+					"module_name": "Garden",
+					"data_type": ["Temperature", "Humidity"],
+					"last_setup": 1577836800,
+					"reachable": true,
+					"firmware": 50,
+					"last_message": 1622548795,
+					"last_seen": 1622548780,
+					"rf_status": 70,
+					"battery_vp": 5400,
+					"battery_percent": 80,
+					"dashboard_data": {
+						"time_utc": 1622548780,
+						"Temperature": 18.5,
+						"Humidity": 60,
+						"min_temp": 12.1,
+						"max_temp": 19.2,
+						"date_min_temp": 1622520000,
+						"date_max_temp": 1622545000,
+						"temp_trend": "down"
+					}
+				},
+				{
+					"_id": "06:00:00:00:00:03",
+					"type": "NAModule2",
+					"module_name": "Wind",
+					"data_type": ["Wind"],
+					"last_setup": 1577836800,
+					"reachable": true,
+					"firmware": 25,
+					"last_message": 1622548795,
+					"last_seen": 1622548780,
+					"rf_status": 60,
+					"battery_vp": 5800,
+					"battery_percent": 90,
+					"dashboard_data": {
+						"time_utc": 1622548780,
+						"WindStrength": 12,
+						"WindAngle": 270,
+						"GustStrength": 25,
+						"GustAngle": 265,
+						"max_wind_str": 30,
+						"max_wind_angle": 260,
+						"date_max_wind_str": 1622530000
+					}
+				},
+				{
+					"_id": "05:00:00:00:00:04",
+					"type": "NAModule3",
+					"module_name": "Rain",
+					"data_type": ["Rain"],
+					"last_setup": 1577836800,
+					"reachable": true,
+					"firmware": 12,
+					"last_message": 1622548795,
+					"last_seen": 1622548780,
+					"rf_status": 65,
+					"battery_vp": 5600,
+					"battery_percent": 85,
+					"dashboard_data": {
+						"time_utc": 1622548780,
+						"Rain": 0.101,
+						"sum_rain_24": 2.3,
+						"sum_rain_1": 0.4
+					}
+				},
+				{
+					"_id": "03:00:00:00:00:05",
+					"type": "NAModule4",
+					"module_name": "Bedroom",
+					"data_type": ["Temperature", "CO2", "Humidity"],
+					"last_setup": 1577836800,
+					"reachable": true,
+					"firmware": 50,
+					"last_message": 1622548795,
+					"last_seen": 1622548780,
+					"rf_status": 55,
+					"battery_vp": 5300,
+					"battery_percent": 75,
+					"dashboard_data": {
+						"time_utc": 1622548780,
+						"Temperature": 20.1,
+						"CO2": 800,
+						"Humidity": 52,
+						"min_temp": 19.5,
+						"max_temp": 20.8,
+						"date_min_temp": 1622530000,
+						"date_max_temp": 1622500000,
+						"temp_trend": "stable"
+					}
+				},
+				{
+					"_id": "03:00:00:00:00:06",
+					"type": "NAModule4",
+					"module_name": "Attic",
+					"data_type": ["Temperature", "CO2", "Humidity"],
+					"last_setup": 1577836800,
+					"reachable": false,
+					"firmware": 50,
+					"last_message": 1622400000,
+					"last_seen": 0,
+					"rf_status": 90,
+					"battery_vp": 4000,
+					"battery_percent": 5
+				}
+			]
+		},
+		{
+			"_id": "70:ee:50:00:00:07",
+			"date_setup": 1577836800,
+			"last_setup": 0,
+			"last_status_store": 1622000000,
+			"last_upgrade": 0,
+			"type": "NAMain",
+			"module_name": "Cottage",
+			"firmware": 181,
+			"wifi_status": 86,
+			"reachable": false,
+			"co2_calibrating": false,
+			"data_type": ["Temperature", "CO2", "Humidity", "Noise", "Pressure"],
+			"place": {"country": "FR", "altitude": 210, "location": [4.83, 45.76]},
+			"read_only": true,
+			"home_id": "5e0a000000000000000000bb",
+			"home_name": "Cottage",
+			"modules": []
+		}
+	],
+	"user": {
+		"mail": "user@example.com",
+		"administrative": {
+			"reg_locale": "fr-FR",
+			"lang": "fr",
+			"country": "FR",
+			"unit": 0,
+			"windunit": 0,
+			"pressureunit": 0,
+			"feel_like_algo": 0
+		}
+	}
+}`
+
+// publicDataFixture is a getpublicdata station with its pressure, outdoor, wind and rain measures (with the API
+// typos on the wind keys)
+const publicDataFixture = `{
+	"_id": "70:ee:50:00:00:10",
+	"place": {"timezone": "Europe/Paris", "country": "FR", "altitude": 40, "location": [2.30, 48.80]},
+	"mark": 10,
+	"measures": {
+		"70:ee:50:00:00:10": {"res": {"1622548700": [1016.4]}, "type": ["pressure"]},
+		"02:00:00:00:00:11": {"res": {"1622548600": [17.9, 64]}, "type": ["temperature", "humidity"]},
+		"06:00:00:00:00:12": {"wind_strengh": 8, "wind_angle": 250, "gust_strenght": 15, "gust_angle": 245, "wind_timeutc": 1622548650},
+		"05:00:00:00:00:13": {"rain_60min": 0.2, "rain_24h": 1.5, "rain_live": 0, "rain_timeutc": 1622548660}
+	},
+	"modules": ["02:00:00:00:00:11", "06:00:00:00:00:12", "05:00:00:00:00:13"],
+	"module_types": {"02:00:00:00:00:11": "NAModule1", "06:00:00:00:00:12": "NAModule2", "05:00:00:00:00:13": "NAModule3"}
+}`
+
+// checkRoundTrip unmarshals fixture into first, marshals it back and verifies that the payload matches the
+// fixture and that it unmarshals into second as it did into first
+func checkRoundTrip(t *testing.T, fixture string, first, second interface{}) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(fixture), first); err != nil {
+		t.Fatalf("can not unmarshal the fixture: %s", err)
+	}
+	data, err := json.Marshal(first)
+	if err != nil {
+		t.Fatalf("can not marshal: %s", err)
+	}
+	// wire format
+	var expected, got interface{}
+	if err = json.Unmarshal([]byte(fixture), &expected); err != nil {
+		t.Fatalf("invalid fixture: %s", err)
+	}
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid marshaled payload: %s", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("marshaled payload does not match the fixture:\n%s", data)
+	}
+	// go values
+	if err = json.Unmarshal(data, second); err != nil {
+		t.Fatalf("can not unmarshal the marshaled payload: %s", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("round trip mismatch:\n%+v\n%+v", first, second)
+	}
+}
+
+func TestStationDataBodyJSON(t *testing.T) {
+	var first, second StationDataBody
+	checkRoundTrip(t, stationDataFixture, &first, &second)
+	// spot check the conversions
+	station := first.Devices[0]
+	if station.LastStatusStore.Unix() != 1622548800 || !station.LastUpgrade.IsZero() {
+		t.Errorf("unexpected station timestamps: %v, %v", station.LastStatusStore, station.LastUpgrade)
+	}
+	if station.Place.Timezone == nil || station.Place.Timezone.String() != "Europe/Paris" {
+		t.Errorf("unexpected station time zone: %v", station.Place.Timezone)
+	}
+	if station.DashboardData.Time.Unix() != 1622548790 || station.DashboardData.TempMaxDate.Unix() != 1622540000 {
+		t.Errorf("unexpected station dashboard times: %+v", station.DashboardData)
+	}
+	modules := station.Modules
+	if modules[0].DashboardDataOutdoor == nil || modules[1].DashboardDataWind == nil ||
+		modules[2].DashboardDataRain == nil || modules[3].DashboardDataIndoor == nil {
+		t.Fatalf("dashboards not decoded according to the module types: %+v", modules)
+	}
+	if modules[1].DashboardDataWind.DateMaxWindStr.Unix() != 1622530000 {
+		t.Errorf("unexpected max wind date: %v", modules[1].DashboardDataWind.DateMaxWindStr)
+	}
+	if unreachable := modules[4]; unreachable.DashboardDataIndoor != nil || !unreachable.LastSeen.IsZero() {
+		t.Errorf("unexpected unreachable module: %+v", unreachable)
+	}
+	if cottage := first.Devices[1]; cottage.Place.Timezone != nil || cottage.DashboardData.IsSet() {
+		t.Errorf("unexpected unreachable station: %+v", cottage)
+	}
+}
+
+func TestPublicStationDataJSON(t *testing.T) {
+	var first, second PublicStationData
+	checkRoundTrip(t, publicDataFixture, &first, &second)
+	// spot check the conversions
+	if len(first.Pressure) != 1 || first.Pressure[0].Time.Unix() != 1622548700 || first.Pressure[0].Pressure != 1016.4 {
+		t.Errorf("unexpected pressure: %+v", first.Pressure)
+	}
+	if first.Outdoor == nil || len(first.Outdoor.Measures) != 1 || first.Outdoor.Measures[0].Humidity != 64 {
+		t.Errorf("unexpected outdoor module: %+v", first.Outdoor)
+	}
+	if first.Wind == nil || first.Wind.Measures.Time.Unix() != 1622548650 || first.Wind.Measures.GustStrength != 15 {
+		t.Errorf("unexpected wind module: %+v", first.Wind)
+	}
+	if first.Rain == nil || first.Rain.Measures.Time.Unix() != 1622548660 || first.Rain.Measures.Rain24h != 1.5 {
+		t.Errorf("unexpected rain module: %+v", first.Rain)
+	}
+}
@@ -8,13 +8,18 @@ import (
 
 // WindMeasures holds measures for the anemometer module
 type WindMeasures struct {
-	Time         time.Time ``                    // not in this form on the orignal payload
+	Time         time.Time `json:"-"`            // not in this form on the orignal payload
 	WindStrength int       `json:"wind_strengh"` // yes the API has a typo on JSON key
 	WindAngle    int       `json:"wind_angle"`
 	GustStrength int       `json:"gust_strenght"` // yes the API has a typo on JSON key
 	GustAngle    int       `json:"gust_angle"`
 }
 
+// IsSet returns true if the measures have been set (false if the payload did not contain them)
+func (wm WindMeasures) IsSet() bool {
+	return !wm.Time.IsZero()
+}
+
 // UnmarshalJSON allows to create a proper payloade on the fly during JSON unmarshaling
 func (wm *WindMeasures) UnmarshalJSON(data []byte) (err error) {
 	// Add tmp type
@@ -31,20 +36,43 @@ func (wm *WindMeasures) UnmarshalJSON(data []byte) (err error) {
 		return
 	}
 	// convert
-	wm.Time = time.Unix(int64(tmp.WindTimestamp), 0)
+	wm.Time = timeFromUnix(int64(tmp.WindTimestamp))
+	return
+}
+
+// MarshalJSON allows to convert go types back to the original payload
+func (wm WindMeasures) MarshalJSON() (data []byte, err error) {
+	// Add tmp type
+	type OriginalMarshal WindMeasures
+	tmp := struct {
+		WindTimestamp int64 `json:"wind_timeutc"`
+		OriginalMarshal
+	}{
+		WindTimestamp:   unixTimestamp(wm.Time),
+		OriginalMarshal: OriginalMarshal(wm),
+	}
+	// Marshal the tmp struct
+	if data, err = json.Marshal(tmp); err != nil {
+		err = fmt.Errorf("failed to marshal the temporary WindMeasures struct: %w", err)
+	}
 	return
 }
 
 // WindModuleDashboardData struct for WindModuleDashboardData
 type WindModuleDashboardData struct {
-	Time           time.Time ``                      // date when data was measured
+	Time           time.Time `json:"-"`              // date when data was measured
 	WindStrength   int       `json:"WindStrength"`   // wind strength (km/h)
 	WindAngle      int       `json:"WindAngle"`      // wind angle
 	GustStrength   int       `json:"GustStrength"`   // gust strength (km/h)
 	GustAngle      int       `json:"GustAngle"`      // gust angle
 	MaxWindStr     int       `json:"max_wind_str"`   // max wind strength (km/h)
 	MaxWindAngle   int       `json:"max_wind_angle"` // max wind angle
-	DateMaxWindStr time.Time ``                      // max wind date
+	DateMaxWindStr time.Time `json:"-"`              // max wind date
+}
+
+// IsSet returns true if the dashboard contains values (false if the payload did not contain them)
+func (wmdd WindModuleDashboardData) IsSet() bool {
+	return !wmdd.Time.IsZero()
 }
 
 // UnmarshalJSON allows to automatically convert data to go types
@@ -63,8 +91,27 @@ func (wmdd *WindModuleDashboardData) UnmarshalJSON(data []byte) (err error) {
 		return
 	}
 	// convert
-	wmdd.Time = time.Unix(tmp.TimeUTC, 0)
-	wmdd.DateMaxWindStr = time.Unix(tmp.DateMaxWindStr, 0)
+	wmdd.Time = timeFromUnix(tmp.TimeUTC)
+	wmdd.DateMaxWindStr = timeFromUnix(tmp.DateMaxWindStr)
+	return
+}
+
+// MarshalJSON allows to convert go types back to the original payload
+func (wmdd WindModuleDashboardData) MarshalJSON() (data []byte, err error) {
+	type OriginalMarshal WindModuleDashboardData
+	tmp := struct {
+		TimeUTC        int64 `json:"time_utc"`          // timestamp when data was measured
+		DateMaxWindStr int64 `json:"date_max_wind_str"` // max wind date
+		OriginalMarshal
+	}{
+		TimeUTC:         unixTimestamp(wmdd.Time),
+		DateMaxWindStr:  unixTimestamp(wmdd.DateMaxWindStr),
+		OriginalMarshal: OriginalMarshal(wmdd),
+	}
+	// Marshal the tmp struct
+	if data, err = json.Marshal(tmp); err != nil {
+		err = fmt.Errorf("can not marshal Wind dashboard tmp struct: %w", err)
+	}
 	return
 }
 
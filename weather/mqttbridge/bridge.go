@@ -0,0 +1,165 @@
+// Package mqttbridge publishes the weather stations dashboards to MQTT, along with the Home Assistant
+// discovery payloads allowing Home Assistant to automatically create the matching sensors.
+package mqttbridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hekmon/go-netatmo/weather"
+)
+
+const (
+	// DefaultTopicPrefix is the prefix used for the state and availability topics if none is set
+	DefaultTopicPrefix = "netatmo"
+	// DefaultDiscoveryPrefix is the default Home Assistant discovery prefix
+	DefaultDiscoveryPrefix = "homeassistant"
+	// PayloadOnline is the availability payload of a reachable device
+	PayloadOnline = "online"
+	// PayloadOffline is the availability payload of an unreachable device
+	PayloadOffline = "offline"
+)
+
+// Publisher is the minimal MQTT client needed by the Bridge. See the paho subpackage (a separate module) for a
+// ready to use implementation.
+type Publisher interface {
+	Publish(topic string, qos byte, retained bool, payload []byte) error
+}
+
+// Config allows to customize the Bridge
+type Config struct {
+	TopicPrefix      string      // prefix of the state and availability topics (default DefaultTopicPrefix)
+	DiscoveryPrefix  string      // Home Assistant discovery prefix (default DefaultDiscoveryPrefix)
+	DisableDiscovery bool        // do not publish the Home Assistant discovery payloads
+	QoS              byte        // QoS used for every publication
+	ErrorHandler     func(error) // called on publication errors when the bridge is running with Run(), can be nil
+}
+
+// Bridge publishes stations and modules data to MQTT
+type Bridge struct {
+	publisher Publisher
+	conf      Config
+	// discovery payloads already sent
+	discovered     map[string]bool
+	discoveredLock sync.Mutex
+}
+
+// New returns a bridge publishing thru publisher
+func New(publisher Publisher, conf Config) *Bridge {
+	if conf.TopicPrefix == "" {
+		conf.TopicPrefix = DefaultTopicPrefix
+	}
+	if conf.DiscoveryPrefix == "" {
+		conf.DiscoveryPrefix = DefaultDiscoveryPrefix
+	}
+	return &Bridge{
+		publisher:  publisher,
+		conf:       conf,
+		discovered: make(map[string]bool),
+	}
+}
+
+// Run polls the stations data with a weather.Watcher and publishes each new measure or availability change
+// until ctx is cancelled. watcherConf Callback is overridden.
+func (b *Bridge) Run(ctx context.Context, wc *weather.Client, watcherConf weather.WatcherConfig) {
+	watcherConf.Callback = func(event weather.Event) {
+		var err error
+		switch event.Type {
+		case weather.EventNewMeasurement, weather.EventBatteryLow, weather.EventBatteryOK:
+			err = b.publishDevice(event.Station, event.Module)
+		case weather.EventModuleReachable, weather.EventModuleUnreachable:
+			err = b.publishAvailability(event.Station.ID, event.Module.ID, event.Module.Reachable)
+		case weather.EventStationOnline, weather.EventStationOffline:
+			err = b.publishAvailability(event.Station.ID, event.Station.ID, event.Type == weather.EventStationOnline)
+		case weather.EventPollError:
+			err = event.Err
+		}
+		if err != nil && b.conf.ErrorHandler != nil {
+			b.conf.ErrorHandler(err)
+		}
+	}
+	wc.NewWatcher(watcherConf).Run(ctx)
+}
+
+// Publish publishes the discovery (once), availability and state payloads of every station and module of data
+func (b *Bridge) Publish(data weather.StationDataBody) (err error) {
+	for stationIndex := range data.Devices {
+		station := &data.Devices[stationIndex]
+		if err = b.publishDevice(station, nil); err != nil {
+			return
+		}
+		for moduleIndex := range station.Modules {
+			if err = b.publishDevice(station, &station.Modules[moduleIndex]); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// publishDevice publishes a station (module is nil) or one of its modules
+func (b *Bridge) publishDevice(station *weather.StationDataBodyDevices, module *weather.Module) (err error) {
+	moduleID := station.ID
+	reachable := station.Reachable
+	if module != nil {
+		moduleID = module.ID
+		reachable = module.Reachable
+	}
+	// discovery
+	if !b.conf.DisableDiscovery {
+		if err = b.publishDiscovery(station, module); err != nil {
+			return
+		}
+	}
+	// availability
+	if err = b.publishAvailability(station.ID, moduleID, reachable); err != nil {
+		return
+	}
+	// state
+	state := make(map[string]interface{})
+	for _, record := range weather.StationDataRecords(weather.StationDataBody{
+		Devices: []weather.StationDataBodyDevices{*station},
+	}) {
+		if record.ModuleID != moduleID {
+			continue
+		}
+		for name, value := range record.Fields {
+			state[name] = value
+		}
+		state["time"] = record.Time.UTC().Format(time.RFC3339)
+	}
+	if module != nil {
+		state[batteryKey] = module.BatteryPercent
+	}
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state of module '%s': %w", moduleID, err)
+	}
+	return b.publisher.Publish(b.stateTopic(station.ID, moduleID), b.conf.QoS, true, payload)
+}
+
+func (b *Bridge) publishAvailability(stationID, moduleID string, reachable bool) error {
+	payload := PayloadOffline
+	if reachable {
+		payload = PayloadOnline
+	}
+	return b.publisher.Publish(b.availabilityTopic(stationID, moduleID), b.conf.QoS, true, []byte(payload))
+}
+
+var topicSanitizer = strings.NewReplacer(":", "", "/", "_", "+", "_", "#", "_", " ", "_")
+
+func sanitize(value string) string {
+	return topicSanitizer.Replace(value)
+}
+
+func (b *Bridge) stateTopic(stationID, moduleID string) string {
+	return fmt.Sprintf("%s/%s/%s/state", b.conf.TopicPrefix, sanitize(stationID), sanitize(moduleID))
+}
+
+func (b *Bridge) availabilityTopic(stationID, moduleID string) string {
+	return fmt.Sprintf("%s/%s/%s/availability", b.conf.TopicPrefix, sanitize(stationID), sanitize(moduleID))
+}
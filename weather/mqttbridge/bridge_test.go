@@ -0,0 +1,299 @@
+package mqttbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hekmon/go-netatmo/weather"
+)
+
+// message is a publication recorded by recordingPublisher
+type message struct {
+	topic    string
+	qos      byte
+	retained bool
+	payload  []byte
+}
+
+// recordingPublisher records every publication, it can be made to fail on a given topic
+type recordingPublisher struct {
+	access    sync.Mutex
+	messages  []message
+	failTopic string
+}
+
+func (rp *recordingPublisher) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	rp.access.Lock()
+	defer rp.access.Unlock()
+	if topic == rp.failTopic {
+		return errors.New("broker unavailable")
+	}
+	rp.messages = append(rp.messages, message{
+		topic:    topic,
+		qos:      qos,
+		retained: retained,
+		payload:  payload,
+	})
+	return nil
+}
+
+// last returns the last message published on topic
+func (rp *recordingPublisher) last(t *testing.T, topic string) message {
+	t.Helper()
+	rp.access.Lock()
+	defer rp.access.Unlock()
+	for index := len(rp.messages) - 1; index >= 0; index-- {
+		if rp.messages[index].topic == topic {
+			return rp.messages[index]
+		}
+	}
+	t.Fatalf("nothing published on '%s'", topic)
+	return message{}
+}
+
+// count returns the number of messages published on topics matching prefix
+func (rp *recordingPublisher) count(prefix string) (count int) {
+	rp.access.Lock()
+	defer rp.access.Unlock()
+	for _, msg := range rp.messages {
+		if strings.HasPrefix(msg.topic, prefix) {
+			count++
+		}
+	}
+	return
+}
+
+const (
+	testStationID = "70:ee:50:00:00:01"
+	testModuleID  = "02:00:00:00:00:02"
+)
+
+func testStationData(moduleReachable bool) weather.StationDataBody {
+	measureTime := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	module := weather.Module{
+		ID:             testModuleID,
+		Type:           weather.ModuleTypeOutdoor,
+		ModuleName:     "Garden",
+		DataType:       []weather.ModuleDataType{weather.ModuleDataTypeTemperature, weather.ModuleDataTypeHumidity},
+		Reachable:      moduleReachable,
+		Firmware:       50,
+		LastMessage:    measureTime,
+		BatteryPercent: 42,
+	}
+	if moduleReachable {
+		module.DashboardDataOutdoor = &weather.OutdoorModuleDashboardData{
+			Time:        measureTime,
+			Temperature: 18.5,
+			Humidity:    60,
+		}
+	}
+	return weather.StationDataBody{
+		Devices: []weather.StationDataBodyDevices{
+			{
+				ID:         testStationID,
+				Type:       weather.ModuleTypeStation,
+				ModuleName: "Living room",
+				HomeName:   "Home",
+				Firmware:   181,
+				Reachable:  true,
+				DataType: []weather.ModuleDataType{
+					weather.ModuleDataTypeTemperature, weather.ModuleDataTypeCO2, weather.ModuleDataTypeHumidity,
+					weather.ModuleDataTypeNoise, weather.ModuleDataTypePressure,
+				},
+				DashboardData: weather.DashboardDataWeatherStation{
+					Time:             measureTime,
+					Temperature:      21.3,
+					CO2:              650,
+					Humidity:         48,
+					Noise:            38,
+					Pressure:         1015.2,
+					AbsolutePressure: 1002.1,
+				},
+				Modules: []weather.Module{module},
+			},
+		},
+	}
+}
+
+func decodeJSON(t *testing.T, payload []byte) (values map[string]interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(payload, &values); err != nil {
+		t.Fatalf("invalid JSON payload %s: %s", payload, err)
+	}
+	return
+}
+
+func TestPublishStateTopics(t *testing.T) {
+	publisher := &recordingPublisher{}
+	bridge := New(publisher, Config{QoS: 1})
+	if err := bridge.Publish(testStationData(true)); err != nil {
+		t.Fatalf("publish failed: %s", err)
+	}
+	// station
+	msg := publisher.last(t, "netatmo/70ee50000001/70ee50000001/state")
+	if !msg.retained || msg.qos != 1 {
+		t.Errorf("station state must be retained with the configured QoS, got retained=%v qos=%d", msg.retained, msg.qos)
+	}
+	state := decodeJSON(t, msg.payload)
+	for key, expected := range map[string]float64{"temperature": 21.3, "co2": 650, "pressure": 1015.2, "absolute_pressure": 1002.1} {
+		if state[key] != expected {
+			t.Errorf("station state '%s': expected %v, got %v", key, expected, state[key])
+		}
+	}
+	if state["time"] != "2021-06-01T12:00:00Z" {
+		t.Errorf("station state time: got %v", state["time"])
+	}
+	if _, found := state[batteryKey]; found {
+		t.Error("station state must not contain a battery level")
+	}
+	// module
+	state = decodeJSON(t, publisher.last(t, "netatmo/70ee50000001/020000000002/state").payload)
+	if state["temperature"] != 18.5 || state["humidity"] != float64(60) {
+		t.Errorf("unexpected module state: %v", state)
+	}
+	if state[batteryKey] != float64(42) {
+		t.Errorf("module state battery: expected 42, got %v", state[batteryKey])
+	}
+}
+
+func TestPublishCustomPrefixes(t *testing.T) {
+	publisher := &recordingPublisher{}
+	bridge := New(publisher, Config{TopicPrefix: "weather", DiscoveryPrefix: "ha"})
+	if err := bridge.Publish(testStationData(true)); err != nil {
+		t.Fatalf("publish failed: %s", err)
+	}
+	publisher.last(t, "weather/70ee50000001/020000000002/state")
+	publisher.last(t, "weather/70ee50000001/020000000002/availability")
+	publisher.last(t, "ha/sensor/netatmo_020000000002_temperature/config")
+	if count := publisher.count("netatmo/") + publisher.count("homeassistant/"); count != 0 {
+		t.Errorf("%d messages published with the default prefixes", count)
+	}
+}
+
+func TestDiscovery(t *testing.T) {
+	publisher := &recordingPublisher{}
+	bridge := New(publisher, Config{})
+	if err := bridge.Publish(testStationData(true)); err != nil {
+		t.Fatalf("publish failed: %s", err)
+	}
+	// station: temperature, min/max are not declared, co2, humidity, noise, pressure and absolute pressure
+	if count := publisher.count("homeassistant/sensor/netatmo_70ee50000001_"); count != 6 {
+		t.Errorf("expected 6 station discovery payloads, got %d", count)
+	}
+	// module: temperature, humidity and battery
+	if count := publisher.count("homeassistant/sensor/netatmo_020000000002_"); count != 3 {
+		t.Errorf("expected 3 module discovery payloads, got %d", count)
+	}
+	msg := publisher.last(t, "homeassistant/sensor/netatmo_70ee50000001_co2/config")
+	if !msg.retained {
+		t.Error("discovery payloads must be retained")
+	}
+	var payload discoveryPayload
+	if err := json.Unmarshal(msg.payload, &payload); err != nil {
+		t.Fatalf("invalid discovery payload: %s", err)
+	}
+	if payload.DeviceClass != "carbon_dioxide" || payload.UnitOfMeasurement != weather.UnitCO2 {
+		t.Errorf("unexpected co2 device class or unit: %+v", payload)
+	}
+	if payload.StateTopic != "netatmo/70ee50000001/70ee50000001/state" ||
+		payload.AvailabilityTopic != "netatmo/70ee50000001/70ee50000001/availability" {
+		t.Errorf("unexpected co2 topics: %+v", payload)
+	}
+	if payload.ValueTemplate != "{{ value_json.co2 }}" {
+		t.Errorf("unexpected co2 value template: %s", payload.ValueTemplate)
+	}
+	if payload.Device.Name != "Home Living room" || payload.Device.ViaDevice != "" {
+		t.Errorf("unexpected station device: %+v", payload.Device)
+	}
+	// module device is linked to its station
+	if err := json.Unmarshal(publisher.last(t, "homeassistant/sensor/netatmo_020000000002_temperature/config").payload, &payload); err != nil {
+		t.Fatalf("invalid discovery payload: %s", err)
+	}
+	if payload.Device.ViaDevice != testStationID || payload.Device.Model != string(weather.ModuleTypeOutdoor) {
+		t.Errorf("unexpected module device: %+v", payload.Device)
+	}
+	// discovery payloads are only sent once
+	before := publisher.count("homeassistant/")
+	if err := bridge.Publish(testStationData(true)); err != nil {
+		t.Fatalf("second publish failed: %s", err)
+	}
+	if after := publisher.count("homeassistant/"); after != before {
+		t.Errorf("discovery payloads sent again: %d before, %d after", before, after)
+	}
+}
+
+func TestDisableDiscovery(t *testing.T) {
+	publisher := &recordingPublisher{}
+	bridge := New(publisher, Config{DisableDiscovery: true})
+	if err := bridge.Publish(testStationData(true)); err != nil {
+		t.Fatalf("publish failed: %s", err)
+	}
+	if count := publisher.count("homeassistant/"); count != 0 {
+		t.Errorf("%d discovery payloads published while disabled", count)
+	}
+	publisher.last(t, "netatmo/70ee50000001/70ee50000001/state")
+}
+
+func TestAvailability(t *testing.T) {
+	publisher := &recordingPublisher{}
+	bridge := New(publisher, Config{})
+	if err := bridge.Publish(testStationData(false)); err != nil {
+		t.Fatalf("publish failed: %s", err)
+	}
+	msg := publisher.last(t, "netatmo/70ee50000001/70ee50000001/availability")
+	if string(msg.payload) != PayloadOnline || !msg.retained {
+		t.Errorf("station availability: expected retained '%s', got '%s' (retained %v)", PayloadOnline, msg.payload, msg.retained)
+	}
+	msg = publisher.last(t, "netatmo/70ee50000001/020000000002/availability")
+	if string(msg.payload) != PayloadOffline {
+		t.Errorf("module availability: expected '%s', got '%s'", PayloadOffline, msg.payload)
+	}
+	// back online
+	if err := bridge.Publish(testStationData(true)); err != nil {
+		t.Fatalf("publish failed: %s", err)
+	}
+	if msg = publisher.last(t, "netatmo/70ee50000001/020000000002/availability"); string(msg.payload) != PayloadOnline {
+		t.Errorf("module availability: expected '%s', got '%s'", PayloadOnline, msg.payload)
+	}
+}
+
+func TestBatterySensor(t *testing.T) {
+	publisher := &recordingPublisher{}
+	bridge := New(publisher, Config{})
+	if err := bridge.Publish(testStationData(false)); err != nil {
+		t.Fatalf("publish failed: %s", err)
+	}
+	var payload discoveryPayload
+	if err := json.Unmarshal(publisher.last(t, "homeassistant/sensor/netatmo_020000000002_battery/config").payload, &payload); err != nil {
+		t.Fatalf("invalid discovery payload: %s", err)
+	}
+	if payload.DeviceClass != "battery" || payload.UnitOfMeasurement != "%" || payload.ValueTemplate != "{{ value_json.battery }}" {
+		t.Errorf("unexpected battery sensor: %+v", payload)
+	}
+	if publisher.count("homeassistant/sensor/netatmo_70ee50000001_battery/") != 0 {
+		t.Error("a battery sensor has been declared for the station")
+	}
+	// the battery level is published even when the module is unreachable
+	state := decodeJSON(t, publisher.last(t, "netatmo/70ee50000001/020000000002/state").payload)
+	if state[batteryKey] != float64(42) {
+		t.Errorf("module state battery: expected 42, got %v", state[batteryKey])
+	}
+}
+
+func TestPublishError(t *testing.T) {
+	publisher := &recordingPublisher{failTopic: "homeassistant/sensor/netatmo_020000000002_humidity/config"}
+	bridge := New(publisher, Config{})
+	if err := bridge.Publish(testStationData(true)); err == nil {
+		t.Fatal("expected the publication error to be returned")
+	}
+	// the failed discovery payload is sent again on the next publication
+	publisher.failTopic = ""
+	if err := bridge.Publish(testStationData(true)); err != nil {
+		t.Fatalf("publish failed: %s", err)
+	}
+	publisher.last(t, "homeassistant/sensor/netatmo_020000000002_humidity/config")
+}
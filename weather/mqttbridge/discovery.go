@@ -0,0 +1,142 @@
+package mqttbridge
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hekmon/go-netatmo/weather"
+)
+
+/*
+	https://www.home-assistant.io/docs/mqtt/discovery/
+*/
+
+const (
+	batteryKey = "battery"
+)
+
+// sensor describes a Home Assistant sensor matching a state key
+type sensor struct {
+	key         string
+	name        string
+	deviceClass string
+	unit        string
+}
+
+// dataTypeSensors lists the sensors to declare for each module data type
+var dataTypeSensors = map[weather.ModuleDataType][]sensor{
+	weather.ModuleDataTypeTemperature: {
+		{key: "temperature", name: "Temperature", deviceClass: "temperature", unit: weather.UnitTemperature},
+	},
+	weather.ModuleDataTypeHumidity: {
+		{key: "humidity", name: "Humidity", deviceClass: "humidity", unit: weather.UnitHumidity},
+	},
+	weather.ModuleDataTypeCO2: {
+		{key: "co2", name: "CO2", deviceClass: "carbon_dioxide", unit: weather.UnitCO2},
+	},
+	weather.ModuleDataTypeNoise: {
+		{key: "noise", name: "Noise", deviceClass: "sound_pressure", unit: weather.UnitNoise},
+	},
+	weather.ModuleDataTypePressure: {
+		{key: "pressure", name: "Pressure", deviceClass: "pressure", unit: weather.UnitPressure},
+		{key: "absolute_pressure", name: "Absolute pressure", deviceClass: "pressure", unit: weather.UnitPressure},
+	},
+	weather.ModuleDataTypeWind: {
+		{key: "wind_strength", name: "Wind strength", deviceClass: "wind_speed", unit: "km/h"},
+		{key: "wind_angle", name: "Wind angle", unit: "°"},
+		{key: "gust_strength", name: "Gust strength", deviceClass: "wind_speed", unit: "km/h"},
+		{key: "gust_angle", name: "Gust angle", unit: "°"},
+	},
+	weather.ModuleDataTypeRain: {
+		{key: "rain", name: "Rain", deviceClass: "precipitation", unit: "mm"},
+		{key: "sum_rain_1", name: "Rain last hour", deviceClass: "precipitation", unit: "mm"},
+		{key: "sum_rain_24", name: "Rain last 24h", deviceClass: "precipitation", unit: "mm"},
+	},
+}
+
+var batterySensor = sensor{
+	key:         batteryKey,
+	name:        "Battery",
+	deviceClass: "battery",
+	unit:        "%",
+}
+
+// discoveryPayload is the Home Assistant MQTT sensor discovery payload
+type discoveryPayload struct {
+	Name              string          `json:"name"`
+	UniqueID          string          `json:"unique_id"`
+	StateTopic        string          `json:"state_topic"`
+	ValueTemplate     string          `json:"value_template"`
+	DeviceClass       string          `json:"device_class,omitempty"`
+	UnitOfMeasurement string          `json:"unit_of_measurement,omitempty"`
+	StateClass        string          `json:"state_class"`
+	AvailabilityTopic string          `json:"availability_topic"`
+	Device            discoveryDevice `json:"device"`
+}
+
+type discoveryDevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Model        string   `json:"model"`
+	Manufacturer string   `json:"manufacturer"`
+	SWVersion    string   `json:"sw_version"`
+	ViaDevice    string   `json:"via_device,omitempty"`
+}
+
+// publishDiscovery publishes the discovery payloads of a station (module is nil) or one of its modules.
+// Payloads are only sent once per sensor.
+func (b *Bridge) publishDiscovery(station *weather.StationDataBodyDevices, module *weather.Module) (err error) {
+	// Gather device infos
+	device := discoveryDevice{
+		Identifiers:  []string{station.ID},
+		Name:         fmt.Sprintf("%s %s", station.HomeName, station.ModuleName),
+		Model:        string(station.Type),
+		Manufacturer: "Netatmo",
+		SWVersion:    fmt.Sprintf("%d", station.Firmware),
+	}
+	dataTypes := station.DataType
+	moduleID := station.ID
+	var sensors []sensor
+	if module != nil {
+		device.Identifiers = []string{module.ID}
+		device.Name = fmt.Sprintf("%s %s", station.HomeName, module.ModuleName)
+		device.Model = string(module.Type)
+		device.SWVersion = fmt.Sprintf("%d", module.Firmware)
+		device.ViaDevice = station.ID
+		dataTypes = module.DataType
+		moduleID = module.ID
+		sensors = append(sensors, batterySensor)
+	}
+	for _, dataType := range dataTypes {
+		sensors = append(sensors, dataTypeSensors[dataType]...)
+	}
+	// Publish each sensor config
+	var payload []byte
+	b.discoveredLock.Lock()
+	defer b.discoveredLock.Unlock()
+	for _, s := range sensors {
+		uniqueID := fmt.Sprintf("netatmo_%s_%s", sanitize(moduleID), s.key)
+		if b.discovered[uniqueID] {
+			continue
+		}
+		if payload, err = json.Marshal(discoveryPayload{
+			Name:              fmt.Sprintf("%s %s", device.Name, s.name),
+			UniqueID:          uniqueID,
+			StateTopic:        b.stateTopic(station.ID, moduleID),
+			ValueTemplate:     fmt.Sprintf("{{ value_json.%s }}", s.key),
+			DeviceClass:       s.deviceClass,
+			UnitOfMeasurement: s.unit,
+			StateClass:        "measurement",
+			AvailabilityTopic: b.availabilityTopic(station.ID, moduleID),
+			Device:            device,
+		}); err != nil {
+			return fmt.Errorf("failed to marshal discovery payload for '%s': %w", uniqueID, err)
+		}
+		topic := fmt.Sprintf("%s/sensor/%s/config", b.conf.DiscoveryPrefix, uniqueID)
+		if err = b.publisher.Publish(topic, b.conf.QoS, true, payload); err != nil {
+			return
+		}
+		b.discovered[uniqueID] = true
+	}
+	return
+}
@@ -0,0 +1,10 @@
+module github.com/hekmon/go-netatmo/weather/mqttbridge/paho
+
+go 1.16
+
+require (
+	github.com/eclipse/paho.mqtt.golang v1.3.5
+	github.com/hekmon/go-netatmo v0.0.0-00010101000000-000000000000
+)
+
+replace github.com/hekmon/go-netatmo => ../../..
@@ -0,0 +1,44 @@
+// Package paho provides a mqttbridge.Publisher built on the Eclipse Paho MQTT client.
+// It is a separate module in order to keep the MQTT client out of the go-netatmo dependencies.
+package paho
+
+import (
+	"fmt"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/hekmon/go-netatmo/weather/mqttbridge"
+)
+
+const (
+	defaultPahoTimeout = 10 * time.Second
+)
+
+// NewPublisher returns a mqttbridge.Publisher using an already connected paho MQTT client.
+// timeout is the maximum time to wait for each publication acknowledgement (10s if 0).
+func NewPublisher(client mqtt.Client, timeout time.Duration) mqttbridge.Publisher {
+	if timeout <= 0 {
+		timeout = defaultPahoTimeout
+	}
+	return pahoPublisher{
+		client:  client,
+		timeout: timeout,
+	}
+}
+
+type pahoPublisher struct {
+	client  mqtt.Client
+	timeout time.Duration
+}
+
+// Publish implements the mqttbridge.Publisher interface
+func (pp pahoPublisher) Publish(topic string, qos byte, retained bool, payload []byte) (err error) {
+	token := pp.client.Publish(topic, qos, retained, payload)
+	if !token.WaitTimeout(pp.timeout) {
+		return fmt.Errorf("publication on '%s' has timed out after %s", topic, pp.timeout)
+	}
+	if err = token.Error(); err != nil {
+		err = fmt.Errorf("publication on '%s' has failed: %w", topic, err)
+	}
+	return
+}
@@ -0,0 +1,86 @@
+package paho
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/hekmon/go-netatmo/weather"
+	"github.com/hekmon/go-netatmo/weather/mqttbridge"
+)
+
+// TestBroker publishes a station thru a local broker (ex: mosquitto) and reads back the retained messages.
+// It only runs if NETATMO_TEST_MQTT_BROKER is set (ex: tcp://localhost:1883).
+func TestBroker(t *testing.T) {
+	broker := os.Getenv("NETATMO_TEST_MQTT_BROKER")
+	if broker == "" {
+		t.Skip("NETATMO_TEST_MQTT_BROKER is not set")
+	}
+	connect := func(clientID string) mqtt.Client {
+		client := mqtt.NewClient(mqtt.NewClientOptions().AddBroker(broker).SetClientID(clientID))
+		if token := client.Connect(); !token.WaitTimeout(5*time.Second) || token.Error() != nil {
+			t.Fatalf("can not connect to '%s': %v", broker, token.Error())
+		}
+		return client
+	}
+	// publish
+	prefix := "netatmo-test-" + time.Now().Format("20060102150405.000000")
+	publisherClient := connect(prefix + "-publisher")
+	defer publisherClient.Disconnect(250)
+	bridge := mqttbridge.New(NewPublisher(publisherClient, 0), mqttbridge.Config{
+		TopicPrefix:     prefix,
+		DiscoveryPrefix: prefix + "-discovery",
+		QoS:             1,
+	})
+	if err := bridge.Publish(weather.StationDataBody{
+		Devices: []weather.StationDataBodyDevices{{
+			ID:        "70:ee:50:00:00:01",
+			Type:      weather.ModuleTypeStation,
+			Reachable: true,
+			DataType:  []weather.ModuleDataType{weather.ModuleDataTypeTemperature},
+			DashboardData: weather.DashboardDataWeatherStation{
+				Time:        time.Now(),
+				Temperature: 21.3,
+			},
+		}},
+	}); err != nil {
+		t.Fatalf("publish failed: %s", err)
+	}
+	// read back the retained messages with another client
+	subscriberClient := connect(prefix + "-subscriber")
+	defer subscriberClient.Disconnect(250)
+	received := make(chan mqtt.Message, 16)
+	expected := map[string]bool{
+		prefix + "/70ee50000001/70ee50000001/state":                          true,
+		prefix + "/70ee50000001/70ee50000001/availability":                   true,
+		prefix + "-discovery/sensor/netatmo_70ee50000001_temperature/config": true,
+	}
+	for topic := range expected {
+		if token := subscriberClient.Subscribe(topic, 1, func(_ mqtt.Client, msg mqtt.Message) {
+			received <- msg
+		}); !token.WaitTimeout(5*time.Second) || token.Error() != nil {
+			t.Fatalf("can not subscribe to '%s': %v", topic, token.Error())
+		}
+	}
+	timeout := time.After(5 * time.Second)
+	for len(expected) > 0 {
+		select {
+		case msg := <-received:
+			if !msg.Retained() {
+				t.Errorf("message on '%s' is not retained", msg.Topic())
+			}
+			delete(expected, msg.Topic())
+		case <-timeout:
+			t.Fatalf("retained messages not received: %v", expected)
+		}
+	}
+	// clean up the retained messages
+	for _, topic := range []string{
+		prefix + "/70ee50000001/70ee50000001/state",
+		prefix + "/70ee50000001/70ee50000001/availability",
+		prefix + "-discovery/sensor/netatmo_70ee50000001_temperature/config",
+	} {
+		publisherClient.Publish(topic, 1, true, []byte{}).WaitTimeout(time.Second)
+	}
+}
@@ -0,0 +1,300 @@
+package weather
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	defaultAggregateMaxAge       = time.Hour
+	defaultAggregateMADThreshold = 3.5
+	defaultAggregateIQRFactor    = 1.5
+)
+
+// DefaultAggregatePercentiles are the percentiles computed by AggregatePublicData() if none are set
+var DefaultAggregatePercentiles = []float64{10, 25, 75, 90}
+
+// OutlierMethod represents the method used to reject outliers before aggregating values
+type OutlierMethod int
+
+const (
+	// OutlierMAD rejects values whose modified z-score (based on the median absolute deviation) exceeds MADThreshold
+	OutlierMAD OutlierMethod = iota
+	// OutlierIQR rejects values outside [Q1 - IQRFactor*IQR, Q3 + IQRFactor*IQR]
+	OutlierIQR
+	// OutlierNone keeps all the values
+	OutlierNone
+)
+
+// String implements the https://golang.org/pkg/fmt/#Stringer interface
+func (om OutlierMethod) String() string {
+	switch om {
+	case OutlierMAD:
+		return "MAD"
+	case OutlierIQR:
+		return "IQR"
+	case OutlierNone:
+		return "none"
+	default:
+		return "<unknown>"
+	}
+}
+
+// GoString implements the https://golang.org/pkg/fmt/#GoStringer interface
+func (om OutlierMethod) GoString() string {
+	return fmt.Sprintf("%s (%d)", om.String(), om)
+}
+
+// AggregateConfig allows to customize AggregatePublicData(). Zero values are replaced by sane defaults.
+type AggregateConfig struct {
+	Now          time.Time     // reference time used to discard old readings (default time.Now())
+	MaxAge       time.Duration // readings older than Now - MaxAge are ignored (default 1h)
+	Outliers     OutlierMethod // outliers rejection method (default OutlierMAD)
+	MADThreshold float64       // modified z-score threshold for OutlierMAD (default 3.5)
+	IQRFactor    float64       // IQR multiplier for OutlierIQR (default 1.5)
+	Percentiles  []float64     // percentiles to compute, between 0 and 100 (default DefaultAggregatePercentiles)
+}
+
+// Aggregate contains the statistics of a measure over a set of stations
+type Aggregate struct {
+	Count       int               // number of stations used
+	Rejected    int               // number of stations rejected as outliers
+	Mean        float64           // arithmetic mean
+	Median      float64           // median
+	Min         float64           // minimum
+	Max         float64           // maximum
+	Percentiles []PercentileValue // requested percentiles, in the AggregateConfig.Percentiles order
+}
+
+// Percentile returns the value of the requested percentile p
+func (a Aggregate) Percentile(p float64) (value float64, found bool) {
+	for _, pv := range a.Percentiles {
+		if pv.Percentile == p {
+			return pv.Value, true
+		}
+	}
+	return 0, false
+}
+
+// PercentileValue contains the value of a requested percentile
+type PercentileValue struct {
+	Percentile float64 // between 0 and 100
+	Value      float64
+}
+
+// WindAggregate contains the statistics of the wind over a set of stations
+type WindAggregate struct {
+	Count       int     // number of stations used
+	Rejected    int     // number of stations rejected as outliers (based on the wind strength)
+	VectorSpeed float64 // magnitude of the vector averaged wind (km/h)
+	VectorAngle float64 // direction of the vector averaged wind (degrees)
+	MeanSpeed   float64 // scalar mean of the wind strength (km/h)
+	MedianSpeed float64 // median of the wind strength (km/h)
+	MaxGust     float64 // maximum gust strength (km/h)
+	MedianGust  float64 // median of the gust strength (km/h)
+	Steadiness  float64 // VectorSpeed / MeanSpeed: 1 when all the stations agree on the direction
+}
+
+// RegionalStats contains aggregated statistics computed from public stations
+type RegionalStats struct {
+	Stations    int // number of stations given
+	Temperature Aggregate
+	Humidity    Aggregate
+	Pressure    Aggregate // sea-level pressure
+	Rain60min   Aggregate // rain sums of the last hour
+	Rain24h     Aggregate // rain sums of the last 24 hours
+	Wind        WindAggregate
+}
+
+// AggregatePublicData computes robust aggregates from public stations (see GetPublicData()).
+// For each station, the most recent reading of each measure is used if it is recent enough.
+func AggregatePublicData(stations []PublicStationData, conf AggregateConfig) (stats RegionalStats) {
+	// defaults
+	if conf.Now.IsZero() {
+		conf.Now = time.Now()
+	}
+	if conf.MaxAge <= 0 {
+		conf.MaxAge = defaultAggregateMaxAge
+	}
+	if conf.MADThreshold <= 0 {
+		conf.MADThreshold = defaultAggregateMADThreshold
+	}
+	if conf.IQRFactor <= 0 {
+		conf.IQRFactor = defaultAggregateIQRFactor
+	}
+	if len(conf.Percentiles) == 0 {
+		conf.Percentiles = DefaultAggregatePercentiles
+	}
+	oldest := conf.Now.Add(-conf.MaxAge)
+	// gather values
+	var (
+		temperature, humidity, pressure, rain60min, rain24h []float64
+		windStrength, windAngle, gustStrength               []float64
+	)
+	stats.Stations = len(stations)
+	for _, station := range stations {
+		if len(station.Pressure) > 0 {
+			if last := station.Pressure[len(station.Pressure)-1]; !last.Time.Before(oldest) {
+				pressure = append(pressure, last.Pressure)
+			}
+		}
+		if station.Outdoor != nil && len(station.Outdoor.Measures) > 0 {
+			if last := station.Outdoor.Measures[len(station.Outdoor.Measures)-1]; !last.Time.Before(oldest) {
+				temperature = append(temperature, last.Temperature)
+				humidity = append(humidity, float64(last.Humidity))
+			}
+		}
+		if station.Rain != nil && !station.Rain.Measures.Time.Before(oldest) {
+			rain60min = append(rain60min, station.Rain.Measures.Rain60min)
+			rain24h = append(rain24h, station.Rain.Measures.Rain24h)
+		}
+		if station.Wind != nil && !station.Wind.Measures.Time.Before(oldest) {
+			windStrength = append(windStrength, float64(station.Wind.Measures.WindStrength))
+			windAngle = append(windAngle, float64(station.Wind.Measures.WindAngle))
+			gustStrength = append(gustStrength, float64(station.Wind.Measures.GustStrength))
+		}
+	}
+	// compute
+	stats.Temperature = newAggregate(temperature, conf)
+	stats.Humidity = newAggregate(humidity, conf)
+	stats.Pressure = newAggregate(pressure, conf)
+	stats.Rain60min = newAggregate(rain60min, conf)
+	stats.Rain24h = newAggregate(rain24h, conf)
+	stats.Wind = newWindAggregate(windStrength, windAngle, gustStrength, conf)
+	return
+}
+
+// newAggregate rejects the outliers of values and computes the statistics of the remaining ones
+func newAggregate(values []float64, conf AggregateConfig) (aggregate Aggregate) {
+	kept := rejectOutliers(values, conf)
+	aggregate.Count = len(kept)
+	aggregate.Rejected = len(values) - len(kept)
+	if len(kept) == 0 {
+		return
+	}
+	sorted := sortedCopy(kept)
+	var sum float64
+	for _, value := range sorted {
+		sum += value
+	}
+	aggregate.Mean = sum / float64(len(sorted))
+	aggregate.Median = percentile(sorted, 50)
+	aggregate.Min = sorted[0]
+	aggregate.Max = sorted[len(sorted)-1]
+	aggregate.Percentiles = make([]PercentileValue, len(conf.Percentiles))
+	for index, p := range conf.Percentiles {
+		aggregate.Percentiles[index] = PercentileValue{
+			Percentile: p,
+			Value:      percentile(sorted, p),
+		}
+	}
+	return
+}
+
+func newWindAggregate(strength, angle, gust []float64, conf AggregateConfig) (aggregate WindAggregate) {
+	var (
+		keep                 = outliersMask(strength, conf)
+		sumX, sumY, sumSpeed float64
+		keptSpeed, keptGust  = make([]float64, 0, len(strength)), make([]float64, 0, len(strength))
+	)
+	for index, ok := range keep {
+		if !ok {
+			aggregate.Rejected++
+			continue
+		}
+		rad := angle[index] * math.Pi / 180
+		sumX += strength[index] * math.Sin(rad)
+		sumY += strength[index] * math.Cos(rad)
+		sumSpeed += strength[index]
+		keptSpeed = append(keptSpeed, strength[index])
+		keptGust = append(keptGust, gust[index])
+	}
+	aggregate.Count = len(keptSpeed)
+	if aggregate.Count == 0 {
+		return
+	}
+	n := float64(aggregate.Count)
+	aggregate.VectorSpeed = math.Hypot(sumX, sumY) / n
+	aggregate.VectorAngle = math.Mod(math.Atan2(sumX, sumY)*180/math.Pi+360, 360)
+	aggregate.MeanSpeed = sumSpeed / n
+	aggregate.MedianSpeed = percentile(sortedCopy(keptSpeed), 50)
+	sortedGust := sortedCopy(keptGust)
+	aggregate.MaxGust = sortedGust[len(sortedGust)-1]
+	aggregate.MedianGust = percentile(sortedGust, 50)
+	if aggregate.MeanSpeed > 0 {
+		aggregate.Steadiness = aggregate.VectorSpeed / aggregate.MeanSpeed
+	}
+	return
+}
+
+// rejectOutliers returns the values which are not outliers
+func rejectOutliers(values []float64, conf AggregateConfig) (kept []float64) {
+	kept = make([]float64, 0, len(values))
+	for index, ok := range outliersMask(values, conf) {
+		if ok {
+			kept = append(kept, values[index])
+		}
+	}
+	return
+}
+
+// outliersMask returns for each value if it must be kept (true) or rejected as an outlier (false)
+func outliersMask(values []float64, conf AggregateConfig) (keep []bool) {
+	keep = make([]bool, len(values))
+	for index := range keep {
+		keep[index] = true
+	}
+	if len(values) < 3 {
+		// not enough values to decide what an outlier is
+		return
+	}
+	sorted := sortedCopy(values)
+	switch conf.Outliers {
+	case OutlierMAD:
+		median := percentile(sorted, 50)
+		deviations := make([]float64, len(values))
+		for index, value := range values {
+			deviations[index] = math.Abs(value - median)
+		}
+		mad := percentile(sortedCopy(deviations), 50)
+		if mad == 0 {
+			return
+		}
+		for index, deviation := range deviations {
+			// 0.6745 makes the MAD consistent with the standard deviation of a normal distribution
+			keep[index] = 0.6745*deviation/mad <= conf.MADThreshold
+		}
+	case OutlierIQR:
+		q1 := percentile(sorted, 25)
+		q3 := percentile(sorted, 75)
+		low := q1 - conf.IQRFactor*(q3-q1)
+		high := q3 + conf.IQRFactor*(q3-q1)
+		for index, value := range values {
+			keep[index] = value >= low && value <= high
+		}
+	}
+	return
+}
+
+func sortedCopy(values []float64) (sorted []float64) {
+	sorted = make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	return
+}
+
+// percentile returns the p (0-100) percentile of sorted values using linear interpolation
+func percentile(sorted []float64, p float64) float64 {
+	switch len(sorted) {
+	case 0:
+		return math.NaN()
+	case 1:
+		return sorted[0]
+	}
+	rank := math.Max(0, math.Min(100, p)) / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
@@ -0,0 +1,112 @@
+package weather
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// RequiredData represents the measurements a public station must provide to be returned by GetPublicData().
+// Values can be combined with the | operator (ex: RequiredDataRain | RequiredDataWind).
+type RequiredData uint8
+
+const (
+	// RequiredDataTemperature only returns stations with an outdoor module
+	RequiredDataTemperature RequiredData = 1 << iota
+	// RequiredDataHumidity only returns stations with an outdoor module
+	RequiredDataHumidity
+	// RequiredDataPressure only returns stations with pressure measures
+	RequiredDataPressure
+	// RequiredDataRain only returns stations with a rain gauge
+	RequiredDataRain
+	// RequiredDataWind only returns stations with an anemometer
+	RequiredDataWind
+	// requiredDataAll is the combination of all the supported values
+	requiredDataAll = RequiredDataTemperature | RequiredDataHumidity | RequiredDataPressure | RequiredDataRain | RequiredDataWind
+)
+
+var requiredDataNames = []struct {
+	value RequiredData
+	name  string
+}{
+	{RequiredDataTemperature, strings.ToLower(string(ModuleDataTypeTemperature))},
+	{RequiredDataHumidity, strings.ToLower(string(ModuleDataTypeHumidity))},
+	{RequiredDataPressure, strings.ToLower(string(ModuleDataTypePressure))},
+	{RequiredDataRain, strings.ToLower(string(ModuleDataTypeRain))},
+	{RequiredDataWind, strings.ToLower(string(ModuleDataTypeWind))},
+}
+
+// String implements the https://golang.org/pkg/fmt/#Stringer interface
+func (rd RequiredData) String() string {
+	names := make([]string, 0, len(requiredDataNames))
+	for _, rdn := range requiredDataNames {
+		if rd&rdn.value != 0 {
+			names = append(names, rdn.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// GoString implements the https://golang.org/pkg/fmt/#GoStringer interface
+func (rd RequiredData) GoString() string {
+	return fmt.Sprintf("%s (%d)", rd.String(), rd)
+}
+
+// Validate returns an error if rd contains unsupported values
+func (rd RequiredData) Validate() error {
+	if unsupported := rd &^ requiredDataAll; unsupported != 0 {
+		return fmt.Errorf("unsupported required data value: %d", unsupported)
+	}
+	return nil
+}
+
+// EncodeValues allows go-querystring to encode the required data (implements https://pkg.go.dev/github.com/google/go-querystring/query#Encoder)
+func (rd RequiredData) EncodeValues(key string, v *url.Values) error {
+	if err := rd.Validate(); err != nil {
+		return err
+	}
+	if rd != 0 {
+		v.Set(key, rd.String())
+	}
+	return nil
+}
+
+// Match returns true if the public station has all the modules needed by rd
+func (rd RequiredData) Match(station PublicStationData) bool {
+	if rd&(RequiredDataTemperature|RequiredDataHumidity) != 0 && (station.Outdoor == nil || len(station.Outdoor.Measures) == 0) {
+		return false
+	}
+	if rd&RequiredDataPressure != 0 && len(station.Pressure) == 0 {
+		return false
+	}
+	if rd&RequiredDataRain != 0 && station.Rain == nil {
+		return false
+	}
+	if rd&RequiredDataWind != 0 && station.Wind == nil {
+		return false
+	}
+	return true
+}
+
+// ParseRequiredData parses a comma separated list of measurements (ex: "rain,wind")
+func ParseRequiredData(value string) (rd RequiredData, err error) {
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, rdn := range requiredDataNames {
+			if rdn.name == name {
+				rd |= rdn.value
+				found = true
+				break
+			}
+		}
+		if !found {
+			err = fmt.Errorf("unsupported required data: %s", name)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,260 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Zone returns the time zone of the place (UTC if unknown)
+func (p Place) Zone() *time.Location {
+	if p.Timezone == nil {
+		return time.UTC
+	}
+	return p.Timezone
+}
+
+// LocalDay returns the bounds of the local day (in the place time zone) containing t: start is the local
+// midnight and end the next one. Days affected by daylight saving time changes are 23 or 25 hours long.
+func (p Place) LocalDay(t time.Time) (start, end time.Time) {
+	local := t.In(p.Zone())
+	start = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	end = time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, local.Location())
+	return
+}
+
+// In returns a copy of the station (and its modules) with all its times expressed in loc
+func (sdbd StationDataBodyDevices) In(loc *time.Location) StationDataBodyDevices {
+	sdbd.DateSetup = timeIn(sdbd.DateSetup, loc)
+	sdbd.LastSetup = timeIn(sdbd.LastSetup, loc)
+	sdbd.LastStatusStore = timeIn(sdbd.LastStatusStore, loc)
+	sdbd.LastUpgrade = timeIn(sdbd.LastUpgrade, loc)
+	sdbd.DashboardData.Time = timeIn(sdbd.DashboardData.Time, loc)
+	sdbd.DashboardData.TempMinDate = timeIn(sdbd.DashboardData.TempMinDate, loc)
+	sdbd.DashboardData.TempMaxDate = timeIn(sdbd.DashboardData.TempMaxDate, loc)
+	if sdbd.Modules != nil {
+		modules := make([]Module, len(sdbd.Modules))
+		for index, module := range sdbd.Modules {
+			modules[index] = module.In(loc)
+		}
+		sdbd.Modules = modules
+	}
+	return sdbd
+}
+
+// InStationTimezone returns a copy of the station (and its modules) with all its times expressed in the station time zone
+func (sdbd StationDataBodyDevices) InStationTimezone() StationDataBodyDevices {
+	return sdbd.In(sdbd.Place.Zone())
+}
+
+// In returns a copy of the module with all its times expressed in loc
+func (m Module) In(loc *time.Location) Module {
+	m.LastSetup = timeIn(m.LastSetup, loc)
+	m.LastMessage = timeIn(m.LastMessage, loc)
+	m.LastSeen = timeIn(m.LastSeen, loc)
+	if m.DashboardDataOutdoor != nil {
+		dd := *m.DashboardDataOutdoor
+		dd.Time = timeIn(dd.Time, loc)
+		dd.DateMinTemp = timeIn(dd.DateMinTemp, loc)
+		dd.DateMaxTemp = timeIn(dd.DateMaxTemp, loc)
+		m.DashboardDataOutdoor = &dd
+	}
+	if m.DashboardDataWind != nil {
+		dd := *m.DashboardDataWind
+		dd.Time = timeIn(dd.Time, loc)
+		dd.DateMaxWindStr = timeIn(dd.DateMaxWindStr, loc)
+		m.DashboardDataWind = &dd
+	}
+	if m.DashboardDataRain != nil {
+		dd := *m.DashboardDataRain
+		dd.Time = timeIn(dd.Time, loc)
+		m.DashboardDataRain = &dd
+	}
+	if m.DashboardDataIndoor != nil {
+		dd := *m.DashboardDataIndoor
+		dd.Time = timeIn(dd.Time, loc)
+		dd.DateMinTemp = timeIn(dd.DateMinTemp, loc)
+		dd.DateMaxTemp = timeIn(dd.DateMaxTemp, loc)
+		m.DashboardDataIndoor = &dd
+	}
+	return m
+}
+
+// In returns a copy of the public station with all its measures times expressed in loc
+func (psd PublicStationData) In(loc *time.Location) PublicStationData {
+	if psd.Pressure != nil {
+		pressure := make(PublicStationDataPressureValues, len(psd.Pressure))
+		for index, value := range psd.Pressure {
+			value.Time = timeIn(value.Time, loc)
+			pressure[index] = value
+		}
+		psd.Pressure = pressure
+	}
+	if psd.Outdoor != nil {
+		outdoor := PublicOutdoorModule{
+			ID:       psd.Outdoor.ID,
+			Measures: make(PublicStationDataOutdoorValues, len(psd.Outdoor.Measures)),
+		}
+		for index, value := range psd.Outdoor.Measures {
+			value.Time = timeIn(value.Time, loc)
+			outdoor.Measures[index] = value
+		}
+		psd.Outdoor = &outdoor
+	}
+	if psd.Wind != nil {
+		wind := *psd.Wind
+		wind.Measures.Time = timeIn(wind.Measures.Time, loc)
+		psd.Wind = &wind
+	}
+	if psd.Rain != nil {
+		rain := *psd.Rain
+		rain.Measures.Time = timeIn(rain.Measures.Time, loc)
+		psd.Rain = &rain
+	}
+	return psd
+}
+
+// InStationTimezone returns a copy of the public station with all its measures times expressed in the station time zone
+func (psd PublicStationData) InStationTimezone() PublicStationData {
+	return psd.In(psd.Place.Zone())
+}
+
+// In returns a copy of the series with all its points times expressed in loc
+func (ms MeasureSeries) In(loc *time.Location) MeasureSeries {
+	if ms.Points != nil {
+		points := make(MeasurePoints, len(ms.Points))
+		for index, point := range ms.Points {
+			point.Time = timeIn(point.Time, loc)
+			points[index] = point
+		}
+		ms.Points = points
+	}
+	return ms
+}
+
+// timeIn keeps zero times (absent values) as is
+func timeIn(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.In(loc)
+}
+
+/*
+	Local day computations
+*/
+
+// Extreme represents a minimum or maximum value and when it was measured
+type Extreme struct {
+	Value float64
+	Time  time.Time
+}
+
+// IsSet returns true if a value has been found
+func (e Extreme) IsSet() bool {
+	return !e.Time.IsZero()
+}
+
+// Extremes returns the minimum and maximum values of a measure type within [start, end[
+func (ms MeasureSeries) Extremes(measureType MeasureType, start, end time.Time) (min, max Extreme) {
+	index := ms.typeIndex(measureType)
+	if index == -1 {
+		return
+	}
+	for _, point := range ms.Points {
+		if point.Time.Before(start) || !point.Time.Before(end) || point.Values[index] == nil {
+			continue
+		}
+		value := *point.Values[index]
+		if !min.IsSet() || value < min.Value {
+			min = Extreme{Value: value, Time: point.Time}
+		}
+		if !max.IsSet() || value > max.Value {
+			max = Extreme{Value: value, Time: point.Time}
+		}
+	}
+	return
+}
+
+// Sum returns the sum of the values of a measure type for the points within ]start, end]. Points are
+// accumulations ending at their timestamp (as rain), hence the point at start belongs to the previous period.
+func (ms MeasureSeries) Sum(measureType MeasureType, start, end time.Time) (sum float64, count int) {
+	index := ms.typeIndex(measureType)
+	if index == -1 {
+		return
+	}
+	for _, point := range ms.Points {
+		if !point.Time.After(start) || point.Time.After(end) || point.Values[index] == nil {
+			continue
+		}
+		sum += *point.Values[index]
+		count++
+	}
+	return
+}
+
+func (ms MeasureSeries) typeIndex(measureType MeasureType) int {
+	for index, mt := range ms.Types {
+		if mt == measureType {
+			return index
+		}
+	}
+	return -1
+}
+
+// LocalDaySummary contains the daily aggregates of a station computed over its local day
+type LocalDaySummary struct {
+	Start          time.Time // local midnight
+	End            time.Time // next local midnight
+	TemperatureMin Extreme   // outdoor module (or the station itself if it has no outdoor module)
+	TemperatureMax Extreme   // outdoor module (or the station itself if it has no outdoor module)
+	RainModuleID   string    // empty if the station has no rain gauge
+	Rain           float64   // rain since local midnight (mm)
+}
+
+// GetLocalDaySummary computes the daily minimum and maximum temperatures and the rain of the local day (in the
+// station time zone) containing day, using GetMeasure(). Use time.Now() to get the current local day values.
+func (wc *Client) GetLocalDaySummary(ctx context.Context, station StationDataBodyDevices, day time.Time) (summary LocalDaySummary, err error) {
+	summary.Start, summary.End = station.Place.LocalDay(day)
+	// find modules
+	temperatureModuleID := ""
+	for _, module := range station.Modules {
+		switch module.Type {
+		case ModuleTypeOutdoor:
+			temperatureModuleID = module.ID
+		case ModuleTypeRainGauge:
+			summary.RainModuleID = module.ID
+		}
+	}
+	// temperature
+	series, _, _, err := wc.GetMeasure(ctx, GetMeasureParameters{
+		DeviceID:  station.ID,
+		ModuleID:  temperatureModuleID,
+		Scale:     MeasureScaleMax,
+		Types:     []MeasureType{MeasureTypeTemperature},
+		DateBegin: summary.Start,
+		DateEnd:   summary.End,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to get the temperatures of the local day: %w", err)
+		return
+	}
+	summary.TemperatureMin, summary.TemperatureMax = series.Extremes(MeasureTypeTemperature, summary.Start, summary.End)
+	// rain
+	if summary.RainModuleID == "" {
+		return
+	}
+	if series, _, _, err = wc.GetMeasure(ctx, GetMeasureParameters{
+		DeviceID:  station.ID,
+		ModuleID:  summary.RainModuleID,
+		Scale:     MeasureScaleMax,
+		Types:     []MeasureType{MeasureTypeRain},
+		DateBegin: summary.Start,
+		DateEnd:   summary.End,
+	}); err != nil {
+		err = fmt.Errorf("failed to get the rain of the local day: %w", err)
+		return
+	}
+	summary.Rain, _ = series.Sum(MeasureTypeRain, summary.Start, summary.End)
+	return
+}
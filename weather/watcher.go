@@ -0,0 +1,336 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// StationMeasureInterval is the interval at which a station pushes its measures to the Netatmo cloud
+	StationMeasureInterval = 10 * time.Minute
+	// StationOfflineThreshold is the duration without status update after which a station is considered offline
+	StationOfflineThreshold = 4 * time.Hour
+)
+
+const (
+	defaultWatcherMargin           = time.Minute
+	defaultWatcherRetryInterval    = 2 * time.Minute
+	defaultWatcherMaxInterval      = 15 * time.Minute
+	defaultWatcherBatteryThreshold = 10
+	defaultWatcherEventsBuffer     = 64
+)
+
+// WatcherConfig allows to customize a Watcher behavior. Zero values are replaced by sane defaults.
+type WatcherConfig struct {
+	Parameters       GetStationDataParameters // parameters used for each GetStationData() call
+	Margin           time.Duration            // delay added after the next expected measure before polling (default 1m)
+	RetryInterval    time.Duration            // delay before polling again when the expected measure is late or on error (default 2m)
+	MaxInterval      time.Duration            // maximum delay between two polls (default 15m)
+	BatteryThreshold int64                    // battery percent under which a module battery is considered low (default 10)
+	OfflineThreshold time.Duration            // duration without status update for a station to be considered offline (default 4h)
+	Callback         func(Event)              // if set, events are sent to it (synchronously) instead of the Events() channel
+}
+
+// EventType represents the type of a Watcher event
+type EventType int
+
+const (
+	// EventNewMeasurement is emitted when a station or a module has pushed new measures
+	EventNewMeasurement EventType = iota
+	// EventModuleUnreachable is emitted when a module was reachable and is not anymore
+	EventModuleUnreachable
+	// EventModuleReachable is emitted when a module was unreachable and is reachable again
+	EventModuleReachable
+	// EventBatteryLow is emitted when a module battery percent goes under the configured threshold
+	EventBatteryLow
+	// EventBatteryOK is emitted when a module battery percent goes back over the configured threshold
+	EventBatteryOK
+	// EventStationOffline is emitted when a station has not updated its status for more than the offline threshold
+	EventStationOffline
+	// EventStationOnline is emitted when an offline station has updated its status again
+	EventStationOnline
+	// EventPollError is emitted when the GetStationData() call has failed
+	EventPollError
+)
+
+// String implements the https://golang.org/pkg/fmt/#Stringer interface
+func (et EventType) String() string {
+	switch et {
+	case EventNewMeasurement:
+		return "new measurement"
+	case EventModuleUnreachable:
+		return "module unreachable"
+	case EventModuleReachable:
+		return "module reachable"
+	case EventBatteryLow:
+		return "battery low"
+	case EventBatteryOK:
+		return "battery ok"
+	case EventStationOffline:
+		return "station offline"
+	case EventStationOnline:
+		return "station online"
+	case EventPollError:
+		return "poll error"
+	default:
+		return "<unknown>"
+	}
+}
+
+// GoString implements the https://golang.org/pkg/fmt/#GoStringer interface
+func (et EventType) GoString() string {
+	return fmt.Sprintf("%s (%d)", et.String(), et)
+}
+
+// Event represents a change detected by a Watcher
+type Event struct {
+	Type    EventType
+	Time    time.Time               // measure time for EventNewMeasurement, detection time otherwise
+	Station *StationDataBodyDevices // station concerned by the event (nil for EventPollError)
+	Module  *Module                 // module concerned by the event, nil if the event concerns the station itself
+	Err     error                   // only set for EventPollError
+}
+
+// Watcher polls GetStationData() on a schedule aligned to the stations measures and emits events on changes.
+// Use Client.NewWatcher() to create one.
+type Watcher struct {
+	client *Client
+	conf   WatcherConfig
+	events chan Event
+	// state
+	stations map[string]*watchedStation
+}
+
+type watchedStation struct {
+	lastMeasure time.Time
+	offline     bool
+	modules     map[string]*watchedModule
+}
+
+type watchedModule struct {
+	lastMeasure time.Time
+	reachable   bool
+	batteryLow  bool
+}
+
+// NewWatcher returns a Watcher using the client to poll the station data. Call Run() to start it.
+func (wc *Client) NewWatcher(conf WatcherConfig) (w *Watcher) {
+	if conf.Margin <= 0 {
+		conf.Margin = defaultWatcherMargin
+	}
+	if conf.RetryInterval <= 0 {
+		conf.RetryInterval = defaultWatcherRetryInterval
+	}
+	if conf.MaxInterval <= 0 {
+		conf.MaxInterval = defaultWatcherMaxInterval
+	}
+	if conf.BatteryThreshold <= 0 {
+		conf.BatteryThreshold = defaultWatcherBatteryThreshold
+	}
+	if conf.OfflineThreshold <= 0 {
+		conf.OfflineThreshold = StationOfflineThreshold
+	}
+	w = &Watcher{
+		client:   wc,
+		conf:     conf,
+		stations: make(map[string]*watchedStation),
+	}
+	if conf.Callback == nil {
+		w.events = make(chan Event, defaultWatcherEventsBuffer)
+	}
+	return
+}
+
+// Events returns the channel on which events are sent. It is closed when Run() returns.
+// Returns nil if a Callback has been set in the WatcherConfig.
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Run polls the stations data until ctx is cancelled. It blocks and must only be called once.
+func (w *Watcher) Run(ctx context.Context) {
+	if w.events != nil {
+		defer close(w.events)
+	}
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			timer.Reset(w.poll(ctx))
+		}
+	}
+}
+
+// poll fetches the station data, emits the events and returns the delay before the next poll
+func (w *Watcher) poll(ctx context.Context) (next time.Duration) {
+	data, _, _, err := w.client.GetStationData(ctx, w.conf.Parameters)
+	now := time.Now()
+	if err != nil {
+		if ctx.Err() == nil {
+			w.emit(ctx, Event{
+				Type: EventPollError,
+				Time: now,
+				Err:  err,
+			})
+		}
+		return w.conf.RetryInterval
+	}
+	var nextExpected time.Time
+	seen := make(map[string]bool, len(data.Devices))
+	for index := range data.Devices {
+		seen[data.Devices[index].ID] = true
+		lastMeasure, offline := w.processStation(ctx, now, &data.Devices[index])
+		// offline, silent or late stations must not drive the schedule of the others
+		if offline || lastMeasure.IsZero() {
+			continue
+		}
+		expected := lastMeasure.Add(StationMeasureInterval + w.conf.Margin)
+		if now.Sub(expected) > w.conf.RetryInterval {
+			continue
+		}
+		if nextExpected.IsZero() || expected.Before(nextExpected) {
+			nextExpected = expected
+		}
+	}
+	// forget the stations not returned anymore
+	for id := range w.stations {
+		if !seen[id] {
+			delete(w.stations, id)
+		}
+	}
+	// compute next poll
+	if nextExpected.IsZero() {
+		return w.conf.MaxInterval
+	}
+	if next = nextExpected.Sub(now); next < w.conf.RetryInterval {
+		next = w.conf.RetryInterval
+	}
+	if next > w.conf.MaxInterval {
+		next = w.conf.MaxInterval
+	}
+	return
+}
+
+// processStation compares a station with its previous state, emits the events and returns its last measure time
+// and its connectivity
+func (w *Watcher) processStation(ctx context.Context, now time.Time, station *StationDataBodyDevices) (lastMeasure time.Time,
+	offline bool) {
+	state, found := w.stations[station.ID]
+	if !found {
+		state = &watchedStation{
+			modules: make(map[string]*watchedModule, len(station.Modules)),
+		}
+		w.stations[station.ID] = state
+	}
+	// station measures
+	lastMeasure = station.DashboardData.Time
+	if station.DashboardData.Time.After(state.lastMeasure) {
+		state.lastMeasure = station.DashboardData.Time
+		w.emit(ctx, Event{
+			Type:    EventNewMeasurement,
+			Time:    station.DashboardData.Time,
+			Station: station,
+		})
+	}
+	// station connectivity
+	offline = !station.Reachable ||
+		(!station.LastStatusStore.IsZero() && now.Sub(station.LastStatusStore) > w.conf.OfflineThreshold)
+	if offline != state.offline {
+		state.offline = offline
+		event := Event{
+			Type:    EventStationOnline,
+			Time:    now,
+			Station: station,
+		}
+		if offline {
+			event.Type = EventStationOffline
+		}
+		w.emit(ctx, event)
+	}
+	// modules
+	seen := make(map[string]bool, len(station.Modules))
+	for index := range station.Modules {
+		module := &station.Modules[index]
+		seen[module.ID] = true
+		if module.LastMessage.After(lastMeasure) {
+			lastMeasure = module.LastMessage
+		}
+		w.processModule(ctx, now, station, module, state)
+	}
+	// forget the modules not returned anymore
+	for id := range state.modules {
+		if !seen[id] {
+			delete(state.modules, id)
+		}
+	}
+	return
+}
+
+// processModule compares a module with its previous state and emits the events
+func (w *Watcher) processModule(ctx context.Context, now time.Time, station *StationDataBodyDevices, module *Module,
+	stationState *watchedStation) {
+	state, found := stationState.modules[module.ID]
+	if !found {
+		// consider a new module as healthy in order to emit events for its current state if it is not
+		state = &watchedModule{
+			reachable: true,
+		}
+		stationState.modules[module.ID] = state
+	}
+	// measures
+	if module.LastMessage.After(state.lastMeasure) {
+		state.lastMeasure = module.LastMessage
+		w.emit(ctx, Event{
+			Type:    EventNewMeasurement,
+			Time:    module.LastMessage,
+			Station: station,
+			Module:  module,
+		})
+	}
+	// reachability
+	if module.Reachable != state.reachable {
+		state.reachable = module.Reachable
+		event := Event{
+			Type:    EventModuleReachable,
+			Time:    now,
+			Station: station,
+			Module:  module,
+		}
+		if !module.Reachable {
+			event.Type = EventModuleUnreachable
+		}
+		w.emit(ctx, event)
+	}
+	// battery (an unreachable module does not report a meaningfull battery level)
+	if module.Reachable {
+		batteryLow := module.BatteryPercent < w.conf.BatteryThreshold
+		if batteryLow != state.batteryLow {
+			state.batteryLow = batteryLow
+			event := Event{
+				Type:    EventBatteryOK,
+				Time:    now,
+				Station: station,
+				Module:  module,
+			}
+			if batteryLow {
+				event.Type = EventBatteryLow
+			}
+			w.emit(ctx, event)
+		}
+	}
+}
+
+func (w *Watcher) emit(ctx context.Context, event Event) {
+	if w.conf.Callback != nil {
+		w.conf.Callback(event)
+		return
+	}
+	select {
+	case w.events <- event:
+	case <-ctx.Done():
+	}
+}